@@ -2,8 +2,10 @@ package httpclient
 
 import (
 	"context"
+	"fmt"
 	"net/http"
 	"net/url"
+	"strings"
 )
 
 // API stores attributes common to multiple requests definition / responses handing.
@@ -14,6 +16,11 @@ type API struct {
 
 	defaultRequestHeaders            http.Header
 	defaultRequestOverrideFunc       RequestOverrideFunc
+	defaultDynamicRequestHeaders     func() http.Header
+	transportErrorWrapper            func(method, url string, err error) error
+	authRefreshOn                    func(*http.Response) bool
+	authRefresh                      func(context.Context) error
+	maxQueryParams                   int
 	defaultResponseHandlers          ResponseStatusHandlers
 	defaultResponseBodySizeReadLimit int64
 }
@@ -26,16 +33,23 @@ func NewAPI(client Doer, serverAddress url.URL) *API {
 		serverAddress:                    serverAddress,
 		defaultRequestHeaders:            make(http.Header),
 		defaultResponseHandlers:          make(map[int]ResponseHandler),
-		defaultResponseBodySizeReadLimit: 1 << 16, //nolint:gomnd // 64ko
+		defaultResponseBodySizeReadLimit: 64 * KB, //nolint:gomnd // 64ko
 	}
 }
 
 // Clone returns a deep clone of the original API.
+// Every default is cloned, including the request override func, which is thus shared with the original API.
 func (api *API) Clone() *API {
 	clone := &API{
 		client:                           api.client,
 		serverAddress:                    *api.URL(""),
 		defaultRequestHeaders:            make(http.Header),
+		defaultRequestOverrideFunc:       api.defaultRequestOverrideFunc,
+		defaultDynamicRequestHeaders:     api.defaultDynamicRequestHeaders,
+		transportErrorWrapper:            api.transportErrorWrapper,
+		authRefreshOn:                    api.authRefreshOn,
+		authRefresh:                      api.authRefresh,
+		maxQueryParams:                   api.maxQueryParams,
 		defaultResponseHandlers:          make(map[int]ResponseHandler),
 		defaultResponseBodySizeReadLimit: api.defaultResponseBodySizeReadLimit,
 	}
@@ -51,12 +65,87 @@ func (api *API) Clone() *API {
 	return clone
 }
 
+// Sub returns a clone of the API whose server address path is suffixed with the provided path prefix.
+// Example: api.Sub("/v1/users").Get("/42") creates a request on /v1/users/42, with all the api defaults.
+func (api *API) Sub(pathPrefix string) *API {
+	sub := api.Clone()
+	if pathPrefix != "" {
+		sub.serverAddress.Path = strings.TrimSuffix(sub.serverAddress.Path, "/") + "/" + strings.TrimPrefix(pathPrefix, "/")
+		sub.serverAddress.RawPath = ""
+	}
+	return sub
+}
+
 // WithRequestOverrideFunc sets a function that allow each requests to be overridden.
 func (api *API) WithRequestOverrideFunc(overrideFunc RequestOverrideFunc) *API {
 	api.defaultRequestOverrideFunc = overrideFunc
 	return api
 }
 
+// WithHMACSigning sets a request override func that signs each request using the provided signer and secret.
+// The signer is free to compute the signature over any canonical form of the request, the request body being readable through req.GetBody.
+// The signature is set in the provided header, prefixed with "keyID:" if the provided keyID is not empty.
+// The previously set request override func, if any, is called before signing the request.
+func (api *API) WithHMACSigning(keyID string, secret []byte, signer func(req *http.Request, secret []byte) (string, error), header string) *API {
+	previousOverrideFunc := api.defaultRequestOverrideFunc
+
+	return api.WithRequestOverrideFunc(func(req *http.Request) (*http.Request, error) {
+		if previousOverrideFunc != nil {
+			var err error
+			if req, err = previousOverrideFunc(req); err != nil {
+				return nil, err
+			}
+		}
+
+		if err := setRequestGetBody(req); err != nil {
+			return nil, err
+		}
+
+		signature, err := signer(req, secret)
+		if err != nil {
+			return nil, fmt.Errorf("unable to sign request: %w", err)
+		}
+
+		if keyID != "" {
+			signature = keyID + ":" + signature
+		}
+
+		req.Header.Set(header, signature)
+
+		return req, nil
+	})
+}
+
+// WithCookieJar sets the cookie jar used to store the cookies set by responses, and to send them with requests.
+// If the API client is an *http.Client, a copy of it is used with its Jar set, otherwise the client is wrapped using DoerWrapCookieJar.
+func (api *API) WithCookieJar(jar http.CookieJar) *API {
+	if client, isHTTPClient := api.client.(*http.Client); isHTTPClient {
+		clientWithJar := *client
+		clientWithJar.Jar = jar
+		api.client = &clientWithJar
+	} else {
+		api.client = DoerWrapCookieJar(api.client, jar)
+	}
+	return api
+}
+
+// WithAuthRefresh sets a function called to refresh the authentication, for instance a token, when a response matches
+// the provided shouldRefresh function (usually responses with 401 status). The request is then built and executed once again,
+// therefore the request override func and dynamic headers can use the refreshed authentication.
+// The request body is replayed using req.GetBody, an error is returned if the request body can't be replayed.
+func (api *API) WithAuthRefresh(shouldRefresh func(*http.Response) bool, refresh func(ctx context.Context) error) *API {
+	api.authRefreshOn = shouldRefresh
+	api.authRefresh = refresh
+	return api
+}
+
+// WithMaxQueryParams sets the maximum number of url query values of each request, above which the request can't be built.
+// It guards against query params coming from untrusted inputs. Zero or negative value disables the limit, which is the default.
+func (api *API) WithMaxQueryParams(n int) *API {
+	api.maxQueryParams = n
+	return api
+}
+
 // WithRequestHeaders sets headers that will be sent to each request.
 func (api *API) WithRequestHeaders(headers http.Header) *API {
 	for key, value := range headers {
@@ -65,12 +154,41 @@ func (api *API) WithRequestHeaders(headers http.Header) *API {
 	return api
 }
 
+// WithDynamicRequestHeaders sets a function called each time a request is built, whose returned headers are sent with the request.
+// Returned headers are not set if the request already defines them. It is useful for headers whose value changes for each request.
+func (api *API) WithDynamicRequestHeaders(fn func() http.Header) *API {
+	api.defaultDynamicRequestHeaders = fn
+	return api
+}
+
+// WithTransportErrorWrapper sets a function used to create the error returned when the request can't be executed,
+// for instance to hide the request url. By default, the error is "unable to execute <method> <url> request: <err>".
+func (api *API) WithTransportErrorWrapper(fn func(method, url string, err error) error) *API {
+	api.transportErrorWrapper = fn
+	return api
+}
+
+// WithBearerToken sets the provided bearer token in the Authorization header sent with each request.
+func (api *API) WithBearerToken(token string) *API {
+	api.defaultRequestHeaders.Set("Authorization", "Bearer "+token)
+	return api
+}
+
 // WithResponseHandler sets a response handler that will be used by default (unless override) for the provided status.
 func (api *API) WithResponseHandler(status int, handler ResponseHandler) *API {
 	api.defaultResponseHandlers[status] = handler
 	return api
 }
 
+// WithResponseHandlers sets response handlers that will be used by default (unless override) for their status.
+// It is equivalent of calling WithResponseHandler for each provided handler.
+func (api *API) WithResponseHandlers(handlers ResponseStatusHandlers) *API {
+	for status, handler := range handlers {
+		api.defaultResponseHandlers[status] = handler
+	}
+	return api
+}
+
 // WithResponseBodySizeReadLimit sets the maximum sized read for any API response.
 // A value of 64ko is set by default. See ResponseBuilder.BodySizeReadLimit for more details on the provided value.
 func (api *API) WithResponseBodySizeReadLimit(bodySizeReadLimit int64) *API {
@@ -94,59 +212,58 @@ func (api *API) URL(endpoint string) *url.URL {
 	return &u
 }
 
-// Head creates a HEAD request builder.
-func (api *API) Head(endpoint string) *RequestBuilder {
-	return NewRequest(http.MethodHead, api.URL(endpoint).String()).
+// newRequest creates a request builder with the api defaults.
+func (api *API) newRequest(method, endpoint string) *RequestBuilder {
+	req := NewRequest(method, api.URL(endpoint).String()).
 		Client(api.client).
 		SetHeaders(api.defaultRequestHeaders).
 		SetOverrideFunc(api.defaultRequestOverrideFunc)
+	req.dynamicHeaders = api.defaultDynamicRequestHeaders
+	req.transportErrorWrapper = api.transportErrorWrapper
+	req.authRefreshOn = api.authRefreshOn
+	req.authRefresh = api.authRefresh
+	req.maxQueryParams = api.maxQueryParams
+	return req
+}
+
+// Head creates a HEAD request builder.
+func (api *API) Head(endpoint string) *RequestBuilder {
+	return api.newRequest(http.MethodHead, endpoint)
 }
 
 // Get creates a GET request builder.
 func (api *API) Get(endpoint string) *RequestBuilder {
-	return NewRequest(http.MethodGet, api.URL(endpoint).String()).
-		Client(api.client).
-		SetHeaders(api.defaultRequestHeaders).
-		SetOverrideFunc(api.defaultRequestOverrideFunc)
+	return api.newRequest(http.MethodGet, endpoint)
 }
 
 // Post creates a POST request builder.
 func (api *API) Post(endpoint string) *RequestBuilder {
-	return NewRequest(http.MethodPost, api.URL(endpoint).String()).
-		Client(api.client).
-		SetHeaders(api.defaultRequestHeaders).
-		SetOverrideFunc(api.defaultRequestOverrideFunc)
+	return api.newRequest(http.MethodPost, endpoint)
 }
 
 // Put creates a PUT request builder.
 func (api *API) Put(endpoint string) *RequestBuilder {
-	return NewRequest(http.MethodPut, api.URL(endpoint).String()).
-		Client(api.client).
-		SetHeaders(api.defaultRequestHeaders).
-		SetOverrideFunc(api.defaultRequestOverrideFunc)
+	return api.newRequest(http.MethodPut, endpoint)
 }
 
 // Patch creates a PATCH request builder.
 func (api *API) Patch(endpoint string) *RequestBuilder {
-	return NewRequest(http.MethodPatch, api.URL(endpoint).String()).
-		Client(api.client).
-		SetHeaders(api.defaultRequestHeaders).
-		SetOverrideFunc(api.defaultRequestOverrideFunc)
+	return api.newRequest(http.MethodPatch, endpoint)
 }
 
 // Delete creates a DELETE request builder.
 func (api *API) Delete(endpoint string) *RequestBuilder {
-	return NewRequest(http.MethodDelete, api.URL(endpoint).String()).
-		Client(api.client).
-		SetHeaders(api.defaultRequestHeaders).
-		SetOverrideFunc(api.defaultRequestOverrideFunc)
+	return api.newRequest(http.MethodDelete, endpoint)
 }
 
 // Do performs the requests and returns a response builder.
 // It differs from NewRequest().Do() by adding defaults to the request / response.
+// The default response body size read limit is not applied if the request defines its own with RequestBuilder.ResponseBodySizeReadLimit.
 func (api *API) Do(ctx context.Context, req *RequestBuilder) *ResponseBuilder {
 	resp := req.Do(ctx)
-	resp = resp.BodySizeReadLimit(api.defaultResponseBodySizeReadLimit)
+	if req.responseBodySizeReadLimit == nil {
+		resp = resp.BodySizeReadLimit(api.defaultResponseBodySizeReadLimit)
+	}
 
 	for httpStatus, responseHandler := range api.defaultResponseHandlers {
 		resp = resp.OnStatus(httpStatus, responseHandler)
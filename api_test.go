@@ -1,11 +1,22 @@
 package httpclient
 
 import (
+	"bytes"
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
+	"net/http/cookiejar"
 	"net/http/httptest"
 	"net/url"
+	"strconv"
+	"strings"
 	"testing"
+	"testing/iotest"
 
 	gocmp "github.com/google/go-cmp/cmp"
 	"gotest.tools/v3/assert"
@@ -58,6 +69,348 @@ func Test_API_Clone(t *testing.T) {
 	assert.Check(t, &original.defaultRequestHeaders != &clone.defaultRequestHeaders)     // same for maps
 	assert.Check(t, &original.defaultResponseHandlers != &clone.defaultResponseHandlers) // same for maps
 	assert.Check(t, original.serverAddress.User != clone.serverAddress.User)             // same for url attributes that also are pointers
+
+	overridden := (&API{}).WithRequestOverrideFunc(func(req *http.Request) (*http.Request, error) {
+		req.Header.Set("Overridden", "true")
+		return req, nil
+	}).Clone()
+	assert.Assert(t, overridden.defaultRequestOverrideFunc != nil)
+	req, err := overridden.defaultRequestOverrideFunc(httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.NilError(t, err)
+	assert.Check(t, req.Header.Get("Overridden") == "true")
+}
+
+func Test_API_Sub(t *testing.T) {
+	httpServer, httpServerURL := newHTTPServerForTesting(t, func(rw http.ResponseWriter, r *http.Request) {
+		assert.Check(t, r.URL.Path == "/api/v1/users/42")
+		assert.Check(t, r.Header.Get("Hello") == "world")
+		assert.Check(t, r.Header.Get("Overridden") == "true")
+		rw.WriteHeader(http.StatusOK)
+	})
+
+	httpServerURL.Path = "/api/"
+	api := NewAPI(httpServer.Client(), httpServerURL).
+		WithRequestHeaders(http.Header{"Hello": {"world"}}).
+		WithRequestOverrideFunc(func(req *http.Request) (*http.Request, error) {
+			req.Header.Set("Overridden", "true")
+			return req, nil
+		})
+	sub := api.Sub("/v1/users")
+
+	assert.Check(t, sub.URL("/42").Path == "/api/v1/users/42")
+	assert.NilError(t, sub.Do(context.Background(), sub.Get("/42")).SuccessOnStatus(http.StatusOK).Error())
+}
+
+func Test_API_WithHMACSigning(t *testing.T) {
+	type ctxKey string
+
+	signer := func(req *http.Request, secret []byte) (string, error) {
+		body, err := req.GetBody()
+		if err != nil {
+			return "", err
+		}
+
+		rawBody, err := io.ReadAll(body)
+		if err != nil {
+			return "", err
+		}
+
+		mac := hmac.New(sha256.New, secret)
+		_, _ = mac.Write([]byte(req.Method + "\n" + req.URL.Path + "\n"))
+		_, _ = mac.Write(rawBody)
+		return hex.EncodeToString(mac.Sum(nil)), nil
+	}
+
+	httpServer, httpServerURL := newHTTPServerForTesting(t, func(rw http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		assert.NilError(t, err)
+		assert.Check(t, string(body) == "hello world")
+		assert.Check(t, r.Header.Get("X-Signature") == "key:0099ab00deef72e7640ba87c23f7cdc7ab39644c25dee48cd40f61f9740ed402")
+		rw.WriteHeader(http.StatusOK)
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		api := NewAPI(httpServer.Client(), httpServerURL).
+			WithRequestOverrideFunc(func(req *http.Request) (*http.Request, error) {
+				return req.WithContext(context.WithValue(req.Context(), ctxKey("key"), "value")), nil
+			}).
+			WithHMACSigning("key", []byte("secret"), signer, "X-Signature")
+
+		req, err := api.Post("/foo").Send(io.MultiReader(strings.NewReader("hello world"))).Request(context.Background())
+		assert.NilError(t, err)
+		assert.Check(t, req.Context().Value(ctxKey("key")).(string) == "value")
+
+		assert.NilError(t, api.Do(context.Background(), api.Post("/foo").Send(io.MultiReader(strings.NewReader("hello world")))).
+			SuccessOnStatus(http.StatusOK).
+			Error(),
+		)
+	})
+
+	t.Run("ko", func(t *testing.T) {
+		t.Run("previous override func failed", func(t *testing.T) {
+			api := NewAPI(httpServer.Client(), httpServerURL).
+				WithRequestOverrideFunc(func(*http.Request) (*http.Request, error) { return nil, errors.New("boom") }).
+				WithHMACSigning("key", []byte("secret"), signer, "X-Signature")
+
+			_, err := api.Post("/foo").Request(context.Background())
+			assert.ErrorContains(t, err, "unable to override request: boom")
+		})
+
+		t.Run("unable to read body", func(t *testing.T) {
+			api := NewAPI(httpServer.Client(), httpServerURL).WithHMACSigning("key", []byte("secret"), signer, "X-Signature")
+
+			_, err := api.Post("/foo").Send(iotest.ErrReader(errors.New("boom"))).Request(context.Background())
+			assert.ErrorContains(t, err, "unable to read body: boom")
+		})
+
+		t.Run("signer failed", func(t *testing.T) {
+			api := NewAPI(httpServer.Client(), httpServerURL).
+				WithHMACSigning("key", []byte("secret"), func(*http.Request, []byte) (string, error) {
+					return "", errors.New("boom")
+				}, "X-Signature")
+
+			_, err := api.Post("/foo").Request(context.Background())
+			assert.ErrorContains(t, err, "unable to sign request: boom")
+		})
+	})
+}
+
+func Test_API_WithCookieJar(t *testing.T) {
+	httpServer, httpServerURL := newHTTPServerForTesting(t, func(rw http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/login":
+			http.SetCookie(rw, &http.Cookie{Name: "session", Value: "42", Path: "/"})
+			rw.WriteHeader(http.StatusNoContent)
+		case "/me":
+			if cookie, err := r.Cookie("session"); err != nil || cookie.Value != "42" {
+				rw.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			rw.WriteHeader(http.StatusOK)
+		default:
+			rw.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	t.Run("provided http client is not modified", func(t *testing.T) {
+		client := httpServer.Client()
+
+		jar, err := cookiejar.New(nil)
+		assert.NilError(t, err)
+
+		NewAPI(client, httpServerURL).WithCookieJar(jar)
+		assert.Check(t, client.Jar == nil)
+	})
+
+	for name, client := range map[string]Doer{
+		"http client": httpServer.Client(),
+		"other doer":  DoerWrapDumpB64(httpServer.Client(), nil),
+	} {
+		client := client
+
+		t.Run(name, func(t *testing.T) {
+			jar, err := cookiejar.New(nil)
+			assert.NilError(t, err)
+
+			api := NewAPI(client, httpServerURL).WithCookieJar(jar)
+
+			assert.ErrorContains(t, api.Execute(context.Background(), api.Get("/me")), "failed with status 401")
+			assert.NilError(t, api.Do(context.Background(), api.Post("/login")).SuccessOnStatus(http.StatusNoContent).Error())
+			assert.NilError(t, api.Do(context.Background(), api.Get("/me")).SuccessOnStatus(http.StatusOK).Error())
+		})
+	}
+}
+
+func Test_API_WithAuthRefresh(t *testing.T) {
+	var received []string
+
+	httpServer, httpServerURL := newHTTPServerForTesting(t, func(rw http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		assert.NilError(t, err)
+		received = append(received, r.Header.Get("Authorization")+" "+string(body))
+
+		if r.Header.Get("Authorization") != "Bearer fresh" {
+			rw.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		rw.WriteHeader(http.StatusOK)
+	})
+
+	newAPI := func(refresh func(ctx context.Context) error) *API {
+		token := "expired"
+		return NewAPI(httpServer.Client(), httpServerURL).
+			WithDynamicRequestHeaders(func() http.Header { return http.Header{"Authorization": {"Bearer " + token}} }).
+			WithAuthRefresh(
+				func(resp *http.Response) bool { return resp.StatusCode == http.StatusUnauthorized },
+				func(ctx context.Context) error {
+					if err := refresh(ctx); err != nil {
+						return err
+					}
+					token = "fresh"
+					return nil
+				},
+			).
+			WithResponseHandler(http.StatusOK, func(*http.Response) error { return nil })
+	}
+
+	t.Run("ok", func(t *testing.T) {
+		received = nil
+
+		var refreshed int
+		api := newAPI(func(context.Context) error { refreshed++; return nil })
+
+		assert.NilError(t, api.Execute(context.Background(), api.Post("/").Send(strings.NewReader("hello"))))
+		assert.NilError(t, api.Execute(context.Background(), api.Get("/")))
+		assert.Equal(t, refreshed, 1)
+		assert.DeepEqual(t, received, []string{"Bearer expired hello", "Bearer fresh hello", "Bearer fresh "})
+	})
+
+	t.Run("request is retried once", func(t *testing.T) {
+		received = nil
+
+		api := NewAPI(httpServer.Client(), httpServerURL).WithAuthRefresh(
+			func(resp *http.Response) bool { return resp.StatusCode == http.StatusUnauthorized },
+			func(context.Context) error { return nil },
+		)
+
+		assert.ErrorContains(t, api.Execute(context.Background(), api.Get("/")), "failed with status 401")
+		assert.Equal(t, len(received), 2)
+	})
+
+	t.Run("refresh fails", func(t *testing.T) {
+		anError := errors.New("an error")
+		api := newAPI(func(context.Context) error { return anError })
+		assert.ErrorIs(t, api.Execute(context.Background(), api.Get("/")), anError)
+	})
+
+	t.Run("body can't be replayed", func(t *testing.T) {
+		api := newAPI(func(context.Context) error { return nil })
+		assert.ErrorContains(t,
+			api.Execute(context.Background(), api.Post("/").Send(io.MultiReader(strings.NewReader("hello")))),
+			"unable to replay request body after authentication refresh: request GetBody is unset",
+		)
+	})
+}
+
+func Test_API_WithMaxQueryParams(t *testing.T) {
+	api := NewAPI(http.DefaultClient, url.URL{Scheme: "http", Host: "localhost"}).WithMaxQueryParams(3)
+
+	_, err := api.Get("/?foo=bar").AddQueryParam("foo", "baz").SetQueryParam("hello", "world").Request(context.Background())
+	assert.NilError(t, err)
+
+	_, err = api.Clone().Get("/").AddQueryParams(url.Values{"foo": {"bar", "baz"}, "hello": {"world", "!"}}).Request(context.Background())
+	assert.Error(t, err, "url query has 4 values, above the limit of 3")
+
+	_, err = api.WithMaxQueryParams(0).Get("/").AddQueryParams(url.Values{"foo": {"bar", "baz"}, "hello": {"world", "!"}}).Request(context.Background())
+	assert.NilError(t, err)
+}
+
+func Test_API_WithDynamicRequestHeaders(t *testing.T) {
+	var (
+		calls    int
+		received []string
+	)
+
+	httpServer, httpServerURL := newHTTPServerForTesting(t, func(rw http.ResponseWriter, r *http.Request) {
+		received = append(received, r.Header.Get("X-Request-Number")+" "+r.Header.Get("X-Static"))
+		rw.WriteHeader(http.StatusOK)
+	})
+
+	api := NewAPI(httpServer.Client(), httpServerURL).
+		WithRequestHeaders(http.Header{"X-Static": {"api"}}).
+		WithDynamicRequestHeaders(func() http.Header {
+			calls++
+			return http.Header{
+				"x-request-number": {strconv.Itoa(calls)},
+				"X-Static":         {"dynamic"},
+			}
+		}).
+		WithResponseHandler(http.StatusOK, func(*http.Response) error { return nil })
+
+	assert.NilError(t, api.Execute(context.Background(), api.Get("/")))
+	assert.NilError(t, api.Clone().Execute(context.Background(), api.Post("/")))
+	assert.NilError(t, api.Execute(context.Background(), api.Get("/").SetHeader("X-Request-Number", "caller")))
+
+	assert.DeepEqual(t, received, []string{"1 api", "2 api", "caller api"})
+}
+
+func Test_API_WithTransportErrorWrapper(t *testing.T) {
+	errTransport := errors.New("connection reset")
+	serverURL := url.URL{Scheme: "http", Host: "localhost", Path: "/secret"}
+
+	api := NewAPI(DoerWrapFaultInjection(http.DefaultClient, FaultConfig{Probability: 1, Error: errTransport}), serverURL)
+
+	err := api.Execute(context.Background(), api.Get("/42"))
+	assert.ErrorIs(t, err, errTransport)
+	assert.Error(t, err, "unable to execute GET http://localhost/secret/42 request: connection reset")
+
+	api = api.WithTransportErrorWrapper(func(method, url string, err error) error {
+		return fmt.Errorf("upstream %s request failed: %w", method, err)
+	})
+
+	err = api.Execute(context.Background(), api.Get("/42"))
+	assert.ErrorIs(t, err, errTransport)
+	assert.Error(t, err, "upstream GET request failed: connection reset")
+
+	err = api.Clone().Execute(context.Background(), api.Post("/42"))
+	assert.Error(t, err, "upstream POST request failed: connection reset")
+}
+
+func Test_API_WithBearerToken(t *testing.T) {
+	api := NewAPI(http.DefaultClient, url.URL{Scheme: "http", Host: "localhost"}).WithBearerToken("foo")
+
+	req, err := api.Get("/").Request(context.Background())
+	assert.NilError(t, err)
+	assert.Equal(t, req.Header.Get("Authorization"), "Bearer foo")
+
+	req, err = api.Get("/").SetBearerToken("bar").Request(context.Background())
+	assert.NilError(t, err)
+	assert.DeepEqual(t, req.Header.Values("Authorization"), []string{"Bearer bar"})
+}
+
+func Test_API_WithResponseHandlers(t *testing.T) {
+	var (
+		errUnauthorized = errors.New("unauthorized")
+		errNotFound     = errors.New("not found")
+	)
+
+	httpServer, httpServerURL := newHTTPServerForTesting(t, func(rw http.ResponseWriter, r *http.Request) {
+		status, err := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/"))
+		assert.NilError(t, err)
+		rw.WriteHeader(status)
+	})
+
+	api := NewAPI(httpServer.Client(), httpServerURL).
+		WithResponseHandler(http.StatusNotFound, func(*http.Response) error { return errors.New("overridden") }).
+		WithResponseHandlers(ResponseStatusHandlers{
+			http.StatusOK:           func(*http.Response) error { return nil },
+			http.StatusUnauthorized: func(*http.Response) error { return errUnauthorized },
+			http.StatusNotFound:     func(*http.Response) error { return errNotFound },
+		})
+
+	assert.NilError(t, api.Execute(context.Background(), api.Get("/200")))
+	assert.ErrorIs(t, api.Execute(context.Background(), api.Get("/401")), errUnauthorized)
+	assert.ErrorIs(t, api.Clone().Execute(context.Background(), api.Get("/404")), errNotFound)
+	assert.ErrorContains(t, api.Execute(context.Background(), api.Get("/418")), "unhandled request status")
+}
+
+func Test_API_WithResponseBodySizeReadLimit(t *testing.T) {
+	assert.Equal(t, KB, 1024)
+	assert.Equal(t, MB, 1024*1024)
+
+	httpServer, httpServerURL := newHTTPServerForTesting(t, func(rw http.ResponseWriter, r *http.Request) {
+		rw.WriteHeader(http.StatusOK)
+		_, err := rw.Write(bytes.Repeat([]byte("a"), 2*KB))
+		assert.NilError(t, err)
+	})
+
+	api := NewAPI(httpServer.Client(), httpServerURL).
+		WithResponseHandler(http.StatusOK, func(*http.Response) error { return nil })
+
+	assert.NilError(t, api.WithResponseBodySizeReadLimit(2*KB).Execute(context.Background(), api.Get("/")))
+	assert.ErrorContains(t, api.WithResponseBodySizeReadLimit(1*KB).Execute(context.Background(), api.Get("/")),
+		"content length 2048 is above read limit 1024",
+	)
 }
 
 func Test_API_URL(t *testing.T) {
@@ -215,6 +568,10 @@ func Test_API_Do(t *testing.T) {
 			assert.Equal(t, int64(121212), api.Do(context.Background(), NewRequest(http.MethodGet, httpServerURL.String())).bodySizeReadLimit)
 		})
 
+		t.Run("max body read size set by request wins", func(t *testing.T) {
+			assert.Equal(t, int64(42), api.Do(context.Background(), NewRequest(http.MethodGet, httpServerURL.String()).ResponseBodySizeReadLimit(42)).bodySizeReadLimit)
+		})
+
 		t.Run("status not handled by default", func(t *testing.T) {
 			assert.ErrorContains(t, api.Do(context.Background(), api.Get("/")).Error(), "unhandled request status")
 		})
@@ -4,13 +4,16 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"encoding/xml"
 	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/textproto"
 	"net/url"
+	"sort"
 	"strings"
+	"time"
 )
 
 // NewRequest returns a new request builder.
@@ -23,7 +26,7 @@ func NewRequest(method, endpoint string) *RequestBuilder {
 
 	endpointURL, err := url.Parse(endpoint)
 	if err != nil {
-		builder.builderError = fmt.Errorf("unable to parse endpoint url %q: %v", endpoint, err)
+		builder.setBuilderError(fmt.Errorf("unable to parse endpoint url %q: %v", endpoint, err))
 	} else {
 		builder.url = *endpointURL
 	}
@@ -40,22 +43,131 @@ type RequestBuilder struct {
 	url    url.URL
 	header http.Header
 
+	dynamicHeaders func() http.Header // set by API, headers set to the request unless already defined
+	cookies        []*http.Cookie
+
 	body          io.Reader
+	bodySize      *int64
+	bodyConsumed  bool
+	bodyOffset    int64 // position of seekable sized bodies when first consumed, to rewind them on the next builds
 	bodyToMarshal any
 	bodyMarshaler func(any) ([]byte, error)
 
+	expectContinue bool
+	forceHTTP1     bool
+	uploadProgress func(bytesSent, total int64)
+
 	overrideFunc RequestOverrideFunc
+
+	label string
+
+	cancelOn <-chan struct{}
+	timeout  time.Duration
+
+	responseBodySizeReadLimit *int64
+
+	transportErrorWrapper func(method, url string, err error) error // set by API, wraps Doer errors
+	authRefreshOn         func(*http.Response) bool                 // set by API, see API.WithAuthRefresh
+	authRefresh           func(context.Context) error               // set by API, see API.WithAuthRefresh
+	maxQueryParams        int                                       // set by API, see API.WithMaxQueryParams
+}
+
+type labelContextKey struct{}
+
+// LabelFromContext returns the label set by RequestBuilder.Label, if any.
+func LabelFromContext(ctx context.Context) (string, bool) {
+	label, found := ctx.Value(labelContextKey{}).(string)
+	return label, found
 }
 
 // RequestOverrideFunc defines the signature to override a request.
 type RequestOverrideFunc func(req *http.Request) (*http.Request, error)
 
+// setBuilderError records the provided error to be returned when the request is built.
+// Only the first recorded error is kept. Builder methods that can fail are expected to use it,
+// as they can't return an error without breaking the chaining.
+func (b *RequestBuilder) setBuilderError(err error) {
+	if b.builderError == nil {
+		b.builderError = err
+	}
+}
+
+// query returns the parsed url query, and records a builder error if the query is malformed.
+func (b *RequestBuilder) query() url.Values {
+	query, err := url.ParseQuery(b.url.RawQuery)
+	if err != nil {
+		b.setBuilderError(fmt.Errorf("unable to parse url query %q: %v", b.url.RawQuery, err))
+	}
+	return query
+}
+
+// Clone returns a copy of the builder, which can build and execute requests independently of the original one.
+// A body that can't be replayed, like a reader set with Send, is read and buffered in memory to be sent by both builders,
+// unless it is already consumed. Seekable bodies set with SendSizedReader are shared and rewound by both builders,
+// which therefore can't build requests concurrently.
+func (b *RequestBuilder) Clone() *RequestBuilder {
+	clone := *b
+	clone.header = b.header.Clone()
+	clone.cookies = append([]*http.Cookie(nil), b.cookies...)
+
+	if b.body == nil || b.bodyConsumed {
+		return &clone
+	}
+
+	if _, isSeeker := b.body.(io.Seeker); isSeeker && b.bodySize != nil {
+		// records the body position for both builders to rewind the body to it
+		if err := b.consumeBody(); err != nil {
+			b.setBuilderError(err)
+		}
+		clone.builderError, clone.bodyConsumed, clone.bodyOffset = b.builderError, b.bodyConsumed, b.bodyOffset
+		return &clone
+	}
+
+	raw, err := io.ReadAll(b.body)
+	if err != nil {
+		b.setBuilderError(fmt.Errorf("unable to buffer body: %v", err))
+		clone.builderError = b.builderError
+	}
+	b.body = bytes.NewReader(raw)
+	clone.body = bytes.NewReader(raw)
+
+	return &clone
+}
+
 // Client overrides the default http client with the provided one.
 func (b *RequestBuilder) Client(client Doer) *RequestBuilder {
 	b.client = client
 	return b
 }
 
+// BaseURL replaces the scheme, the user info and the host of the request url with the ones of the provided url.
+// If the provided url has a path, it is prepended to the request url path, keeping both paths escaping,
+// like the one set by PathReplacerEscaped. The request url query is kept untouched.
+// It is useful to redirect one request of an API to another host, while keeping API defaults.
+func (b *RequestBuilder) BaseURL(u url.URL) *RequestBuilder {
+	b.url.Scheme = u.Scheme
+	b.url.User = u.User
+	b.url.Host = u.Host
+
+	if u.Path != "" {
+		rawPath := strings.TrimSuffix(u.EscapedPath(), "/") + b.url.EscapedPath()
+		b.url.Path = strings.TrimSuffix(u.Path, "/") + b.url.Path
+		b.url.RawPath = rawPath
+	}
+
+	return b
+}
+
+// When applies the provided function on the builder only if the provided condition is true.
+// It helps to keep conditional building inside the chain.
+// Example: NewRequest(...).When(verbose, func(b *RequestBuilder) *RequestBuilder { return b.SetHeader("X-Debug", "1") }).
+func (b *RequestBuilder) When(cond bool, fn func(*RequestBuilder) *RequestBuilder) *RequestBuilder {
+	if !cond {
+		return b
+	}
+	return fn(b)
+}
+
 // SetHeader replaces the value of the request header with the provided value.
 func (b *RequestBuilder) SetHeader(key, value string, values ...string) *RequestBuilder {
 	key = textproto.CanonicalMIMEHeaderKey(key)
@@ -87,18 +199,73 @@ func (b *RequestBuilder) AddHeaders(header http.Header) *RequestBuilder {
 	return b
 }
 
+// DelHeader removes the provided header, if set. As SetHeaders and AddHeaders don't canonicalize keys,
+// the header is removed whatever the case of its key.
+func (b *RequestBuilder) DelHeader(key string) *RequestBuilder {
+	for k := range b.header {
+		if strings.EqualFold(k, key) {
+			delete(b.header, k)
+		}
+	}
+	return b
+}
+
+// AddCookie adds the provided cookie to the request. Cookies are added to the Cookie header when the request is built,
+// after the headers, therefore a Cookie header set by SetHeader does not discard them.
+func (b *RequestBuilder) AddCookie(cookie *http.Cookie) *RequestBuilder {
+	b.cookies = append(b.cookies, cookie)
+	return b
+}
+
+// CookiesFromJar adds the cookies stored in the provided jar for the request url, as known when this method is called.
+// It works with any Doer, unlike http.Client.Jar, see also DoerWrapCookieJar.
+func (b *RequestBuilder) CookiesFromJar(jar http.CookieJar) *RequestBuilder {
+	u := b.url
+	for _, cookie := range jar.Cookies(&u) {
+		b.AddCookie(cookie)
+	}
+	return b
+}
+
+// SetBearerToken replaces the value of the Authorization header with the provided bearer token.
+func (b *RequestBuilder) SetBearerToken(token string) *RequestBuilder {
+	return b.SetHeader("Authorization", "Bearer "+token)
+}
+
+// ExpectsJSON advertises, using the Accept header, that a JSON response is expected.
+func (b *RequestBuilder) ExpectsJSON() *RequestBuilder {
+	return b.SetHeader("Accept", "application/json")
+}
+
+// SetRawQuery replaces the request url query with the provided already encoded query, used verbatim.
+// It is useful to control the order or the encoding of query parameters, as url.Values sorts them by key.
+// Any subsequent call to query params setters re-encodes the query, and loses the custom order.
+func (b *RequestBuilder) SetRawQuery(raw string) *RequestBuilder {
+	b.url.RawQuery = raw
+	return b
+}
+
 // SetQueryParam replaces the provided value to the provided query parameter.
 func (b *RequestBuilder) SetQueryParam(key, value string, values ...string) *RequestBuilder {
-	query := b.url.Query()
+	query := b.query()
 	query[key] = append([]string{value}, values...)
 	b.url.RawQuery = query.Encode()
 	return b
 }
 
+// SetQueryParamIfNotEmpty is like SetQueryParam, but only sets the provided query parameter if the value is not empty.
+// It is useful for optional filters.
+func (b *RequestBuilder) SetQueryParamIfNotEmpty(key, value string) *RequestBuilder {
+	if value == "" {
+		return b
+	}
+	return b.SetQueryParam(key, value)
+}
+
 // SetQueryParams replaces the provided value to the provided query parameters.
 // It does not replace all the request query parameters with provided query parameters (it is equivalent of calling SetQueryParam for each provided query parameter).
 func (b *RequestBuilder) SetQueryParams(params url.Values) *RequestBuilder {
-	query := b.url.Query()
+	query := b.query()
 	for key, values := range params {
 		query[key] = values
 	}
@@ -109,7 +276,7 @@ func (b *RequestBuilder) SetQueryParams(params url.Values) *RequestBuilder {
 
 // AddQueryParam sets / appends the provided value to the provided query parameter.
 func (b *RequestBuilder) AddQueryParam(key, value string, values ...string) *RequestBuilder {
-	query := b.url.Query()
+	query := b.query()
 	for _, value := range append([]string{value}, values...) {
 		query.Add(key, value)
 	}
@@ -120,7 +287,7 @@ func (b *RequestBuilder) AddQueryParam(key, value string, values ...string) *Req
 
 // AddQueryParams sets / appends the provided value to the provided query parameters.
 func (b *RequestBuilder) AddQueryParams(params url.Values) *RequestBuilder {
-	query := b.url.Query()
+	query := b.query()
 
 	for key, values := range params {
 		for _, value := range values {
@@ -132,33 +299,242 @@ func (b *RequestBuilder) AddQueryParams(params url.Values) *RequestBuilder {
 	return b
 }
 
+// DelQueryParam removes all the values of the provided query parameter, if set.
+func (b *RequestBuilder) DelQueryParam(key string) *RequestBuilder {
+	query := b.query()
+	query.Del(key)
+	b.url.RawQuery = query.Encode()
+	return b
+}
+
+// SetQueryParamsFromMap replaces the provided value to the provided query parameters.
+// It is equivalent of calling SetQueryParams with single-value url.Values.
+func (b *RequestBuilder) SetQueryParamsFromMap(params map[string]string) *RequestBuilder {
+	return b.SetQueryParams(queryParamsFromMap(params))
+}
+
+// AddQueryParamsFromMap sets / appends the provided value to the provided query parameters.
+// It is equivalent of calling AddQueryParams with single-value url.Values.
+func (b *RequestBuilder) AddQueryParamsFromMap(params map[string]string) *RequestBuilder {
+	return b.AddQueryParams(queryParamsFromMap(params))
+}
+
+func queryParamsFromMap(params map[string]string) url.Values {
+	values := make(url.Values, len(params))
+	for key, value := range params {
+		values[key] = []string{value}
+	}
+	return values
+}
+
+// SetQueryParamsFromStruct sets the query parameters encoded from the provided struct, see EncodeValues.
+// Struct fields names are read from the DefaultValuesTag tag.
+func (b *RequestBuilder) SetQueryParamsFromStruct(v any) *RequestBuilder {
+	params, err := EncodeValues(v, DefaultValuesTag)
+	if err != nil {
+		b.setBuilderError(fmt.Errorf("unable to encode query params: %w", err))
+		return b
+	}
+	return b.SetQueryParams(params)
+}
+
 // PathReplacer replaces any matching occurrences of the provided pattern inside the url path, with the provided replacement.
 // It is useful to keep the url provided to NewRequest readable and searchable.
+// The replacement is not escaped: a replacement containing a slash adds path segments, see PathReplacerEscaped otherwise.
 // Example: NewRequest("PUT", "/users/{userID}/email").PathReplacer({"{userID}", userID).
 func (b *RequestBuilder) PathReplacer(pattern, replaceWith string) *RequestBuilder {
 	b.url.Path = strings.ReplaceAll(b.url.Path, pattern, replaceWith)
 	return b
 }
 
+// PathReplacerEscaped is like PathReplacer but the replacement is escaped, to be used as a single path segment.
+// Example: NewRequest("GET", "/users/{id}").PathReplacerEscaped("{id}", "a/b") creates a request on /users/a%2Fb.
+// As the escaped path is kept aside the url path, it should be called after PathReplacer, which would discard it.
+func (b *RequestBuilder) PathReplacerEscaped(pattern, replaceWith string) *RequestBuilder {
+	escapedPattern := (&url.URL{Path: pattern}).EscapedPath()
+	rawPath := strings.ReplaceAll(b.url.EscapedPath(), escapedPattern, url.PathEscape(replaceWith))
+
+	b.url.Path = strings.ReplaceAll(b.url.Path, pattern, replaceWith)
+	b.url.RawPath = rawPath
+	return b
+}
+
+// PathReplacerMap replaces, for each provided pattern, any matching occurrences inside the url path with its replacement.
+// It is equivalent of calling PathReplacer for each pattern, patterns being replaced in lexical order to be deterministic.
+// Example: NewRequest("GET", "/orgs/{org}/repos/{repo}").PathReplacerMap(map[string]string{"{org}": org, "{repo}": repo}).
+func (b *RequestBuilder) PathReplacerMap(replacements map[string]string) *RequestBuilder {
+	patterns := make([]string, 0, len(replacements))
+	for pattern := range replacements {
+		patterns = append(patterns, pattern)
+	}
+	sort.Strings(patterns)
+
+	for _, pattern := range patterns {
+		b.PathReplacer(pattern, replacements[pattern])
+	}
+	return b
+}
+
 // SendForm sets the provided values as url-encoded form values to the request body, with Content-Type header.
 func (b *RequestBuilder) SendForm(values url.Values) *RequestBuilder {
 	b.body = strings.NewReader(values.Encode())
+	b.bodySize = nil
+	b.bodyConsumed = false
 	b.SetHeader("Content-Type", "application/x-www-form-urlencoded")
 	return b
 }
 
-// SendJSON sets the provided object, marshaled in JSON, to the request body, with Content-Type header.
-func (b *RequestBuilder) SendJSON(obj any) *RequestBuilder {
+// SendFormRaw sets the provided pre-encoded form to the request body, with Content-Type header.
+// Unlike SendForm, the body is sent as is, which gives full control over keys order and encoding.
+func (b *RequestBuilder) SendFormRaw(encoded string) *RequestBuilder {
+	b.body = strings.NewReader(encoded)
+	b.bodySize = nil
+	b.bodyConsumed = false
+	b.SetHeader("Content-Type", "application/x-www-form-urlencoded")
+	return b
+}
+
+// SendFormFromStruct sets the form encoded from the provided struct to the request body, see EncodeValues.
+// Struct fields names are read from the DefaultValuesTag tag.
+func (b *RequestBuilder) SendFormFromStruct(v any) *RequestBuilder {
+	values, err := EncodeValues(v, DefaultValuesTag)
+	if err != nil {
+		b.setBuilderError(fmt.Errorf("unable to encode form: %w", err))
+		return b
+	}
+	return b.SendForm(values)
+}
+
+// SendMarshaled sets the provided object, marshaled with the provided marshaler when the request is built,
+// to the request body, with the provided Content-Type header. It is useful to use encodings like msgpack or protobuf.
+func (b *RequestBuilder) SendMarshaled(obj any, marshaler func(any) ([]byte, error), contentType string) *RequestBuilder {
 	b.bodyToMarshal = obj
-	b.bodyMarshaler = json.Marshal
-	b.SetHeader("Content-Type", "application/json")
+	b.bodyMarshaler = marshaler
+	b.SetHeader("Content-Type", contentType)
 	return b
 }
 
+// SendJSON sets the provided object, marshaled in JSON, to the request body, with Content-Type header.
+func (b *RequestBuilder) SendJSON(obj any) *RequestBuilder {
+	return b.SendMarshaled(obj, json.Marshal, "application/json")
+}
+
+// SendXML sets the provided object, marshaled in XML, to the request body, with Content-Type header.
+func (b *RequestBuilder) SendXML(obj any) *RequestBuilder {
+	return b.SendMarshaled(obj, xml.Marshal, "application/xml")
+}
+
+// SendJSONIndent sets the provided object, marshaled in indented JSON, to the request body, with Content-Type header.
+// See json.MarshalIndent for more details on the provided prefix and indent.
+func (b *RequestBuilder) SendJSONIndent(obj any, prefix, indent string) *RequestBuilder {
+	return b.SendMarshaled(obj, func(obj any) ([]byte, error) { return json.MarshalIndent(obj, prefix, indent) }, "application/json")
+}
+
+// JSONPatchOp defines one operation of a JSON patch document, as defined in RFC 6902.
+type JSONPatchOp struct {
+	Op    string `json:"op"`
+	Path  string `json:"path"`
+	From  string `json:"from,omitempty"`
+	Value any    `json:"value,omitempty"`
+}
+
+// MarshalJSON implements json.Marshaler. The value member is always set for add, replace, and test operations,
+// as null is a valid value for them, and omitted if nil for other operations.
+func (op JSONPatchOp) MarshalJSON() ([]byte, error) {
+	type jsonPatchOp JSONPatchOp // does not implement json.Marshaler, which avoids infinite recursion
+
+	if op.Value != nil || (op.Op != "add" && op.Op != "replace" && op.Op != "test") {
+		return json.Marshal(jsonPatchOp(op))
+	}
+
+	return json.Marshal(struct {
+		jsonPatchOp
+		Value any `json:"value"`
+	}{jsonPatchOp: jsonPatchOp(op)})
+}
+
+// SendJSONPatch sets the provided operations, marshaled as a JSON patch document, to the request body, with Content-Type header.
+func (b *RequestBuilder) SendJSONPatch(ops []JSONPatchOp) *RequestBuilder {
+	return b.SendMarshaled(ops, json.Marshal, "application/json-patch+json")
+}
+
+// SendJSONMergePatch sets the provided object, marshaled in JSON, to the request body, with merge patch Content-Type header (RFC 7396).
+func (b *RequestBuilder) SendJSONMergePatch(obj any) *RequestBuilder {
+	return b.SendMarshaled(obj, json.Marshal, "application/merge-patch+json")
+}
+
+// SendJSONLines sets the provided items, each marshaled in JSON on its own line, to the request body, with Content-Type header.
+func (b *RequestBuilder) SendJSONLines(items []any) *RequestBuilder {
+	return b.SendMarshaled(items, func(any) ([]byte, error) {
+		buf := new(bytes.Buffer)
+		encoder := json.NewEncoder(buf)
+
+		for i, item := range items {
+			if err := encoder.Encode(item); err != nil {
+				return nil, fmt.Errorf("unable to marshal item %d: %w", i, err)
+			}
+		}
+
+		return buf.Bytes(), nil
+	}, "application/x-ndjson")
+}
+
 // Send sets the provided body to be used as the request body, with Content-Type octet-stream.
 func (b *RequestBuilder) Send(body io.Reader) *RequestBuilder {
+	return b.SendWithContentType(body, "application/octet-stream")
+}
+
+// SendWithContentType sets the provided body to be used as the request body, with the provided Content-Type.
+// It is useful to send pre-serialized bodies, like application/vnd.api+json ones.
+func (b *RequestBuilder) SendWithContentType(body io.Reader, contentType string) *RequestBuilder {
 	b.body = body
-	b.SetHeader("Content-Type", "application/octet-stream")
+	b.bodySize = nil
+	b.bodyConsumed = false
+	b.SetHeader("Content-Type", contentType)
+	return b
+}
+
+// SendText sets the provided text to be used as the request body, with Content-Type text/plain.
+func (b *RequestBuilder) SendText(body string) *RequestBuilder {
+	return b.SendWithContentType(strings.NewReader(body), "text/plain; charset=utf-8")
+}
+
+// SendSizedReader sets the provided reader to be used as the request body, with the provided size as Content-Length,
+// and the provided content type as Content-Type header, if not empty. The body can only be replayed, for instance on redirects,
+// if the reader implements io.Seeker, in which case req.GetBody seeks the reader back to its position when the request is built.
+func (b *RequestBuilder) SendSizedReader(r io.Reader, size int64, contentType string) *RequestBuilder {
+	b.body = r
+	b.bodySize = &size
+	b.bodyConsumed = false
+	if contentType != "" {
+		b.SetHeader("Content-Type", contentType)
+	}
+	return b
+}
+
+// Expect100Continue sets the Expect header to 100-continue, which allows the server to reject the request before the body is sent.
+// Honoring this header is the role of the transport: for http.Transport, ExpectContinueTimeout must be set to a non-zero value,
+// otherwise the body is sent right away without waiting for the server.
+// As the transport may have to send the body again, a body that cannot be replayed is buffered in memory when the request is built.
+func (b *RequestBuilder) Expect100Continue() *RequestBuilder {
+	b.expectContinue = true
+	b.SetHeader("Expect", "100-continue")
+	return b
+}
+
+// ForceHTTP1 forces the request to be made using HTTP/1.1, for servers that mis-negotiate HTTP/2.
+// As the protocol is negotiated by the transport, the client must be an *http.Client using an *http.Transport,
+// otherwise the request fails. The request is executed by a copy of the client transport with HTTP/2 disabled
+// and keep-alives disabled, as connections of the copy can't be reused by further requests.
+func (b *RequestBuilder) ForceHTTP1() *RequestBuilder {
+	b.forceHTTP1 = true
+	return b
+}
+
+// OnUploadProgress sets a callback called each time a part of the request body is read by the transport.
+// The callback receives the amount of bytes sent so far, and the total size of the body, or -1 if unknown.
+func (b *RequestBuilder) OnUploadProgress(fn func(bytesSent, total int64)) *RequestBuilder {
+	b.uploadProgress = fn
 	return b
 }
 
@@ -168,12 +544,71 @@ func (b *RequestBuilder) SetOverrideFunc(overrideFunc RequestOverrideFunc) *Requ
 	return b
 }
 
+// WithoutOverride removes the function set to override the request, if any, including the one set by API.WithRequestOverrideFunc.
+// It is useful to build a request without the API defaults, like request signing, without cloning the API.
+func (b *RequestBuilder) WithoutOverride() *RequestBuilder {
+	b.overrideFunc = nil
+	return b
+}
+
+// Label sets a logical operation name (like "CreateUser") to the request context.
+// Doer wrappers, for instance for logging or metrics, can read it using LabelFromContext.
+func (b *RequestBuilder) Label(name string) *RequestBuilder {
+	b.label = name
+	return b
+}
+
+// CancelOn cancels the request once the provided channel is closed, like cancelling the request context would.
+// It bridges channel-based cancellation patterns; the request is cancelled while its response body is not closed.
+func (b *RequestBuilder) CancelOn(ch <-chan struct{}) *RequestBuilder {
+	b.cancelOn = ch
+	return b
+}
+
+// Timeout sets the maximum duration of the request, the request context being derived using context.WithTimeout when calling Do.
+// The timeout includes the time spent to read the response body: the context is released once the response body is closed,
+// which is done by ResponseBuilder.Error once the response is handled, or right away if the request fails.
+func (b *RequestBuilder) Timeout(d time.Duration) *RequestBuilder {
+	b.timeout = d
+	return b
+}
+
+// ResponseBodySizeReadLimit sets the maximum sized read for the response of this request.
+// It takes precedence over the default set by API.WithResponseBodySizeReadLimit, regardless of the order of calls.
+// See ResponseBuilder.BodySizeReadLimit for more details on the provided value.
+func (b *RequestBuilder) ResponseBodySizeReadLimit(bodySizeReadLimit int64) *RequestBuilder {
+	b.responseBodySizeReadLimit = &bodySizeReadLimit
+	return b
+}
+
 // Request builds the request.
+// Bodies set with Send or SendForm are consumed by the built request, therefore building another request
+// from the same builder fails, unless a new body is set or the builder is cloned beforehand, see Clone.
+// Marshaled bodies (like with SendJSON) are marshaled on each build, and seekable bodies set with SendSizedReader
+// are seeked back to their initial position.
+// The built request body can be replayed using req.GetBody, for instance on redirects or by DoerWrapRetry, if the body is in memory
+// (marshaled bodies, forms, bytes or strings readers), or if it implements io.Seeker and is set with SendSizedReader.
+// Other bodies can't be replayed, req.GetBody being unset.
 func (b *RequestBuilder) Request(ctx context.Context) (*http.Request, error) {
 	if b.builderError != nil {
 		return nil, b.builderError
 	}
 
+	if b.maxQueryParams > 0 {
+		var count int
+		for _, values := range b.query() {
+			count += len(values)
+		}
+		if b.builderError != nil {
+			return nil, b.builderError
+		}
+		if count > b.maxQueryParams {
+			return nil, fmt.Errorf("url query has %d values, above the limit of %d", count, b.maxQueryParams)
+		}
+	}
+
+	body := b.body
+
 	if b.bodyToMarshal != nil {
 		if b.body != nil {
 			return nil, errors.New("body to marshal is set but body is already set")
@@ -188,43 +623,263 @@ func (b *RequestBuilder) Request(ctx context.Context) (*http.Request, error) {
 			return nil, fmt.Errorf("unable to marshal body: %w", err)
 		}
 
-		b.body = bytes.NewReader(raw)
+		body = bytes.NewReader(raw)
+	} else if body != nil {
+		if err := b.consumeBody(); err != nil {
+			return nil, err
+		}
 	}
 
-	req, err := http.NewRequestWithContext(ctx, b.method, b.url.String(), b.body)
+	if b.expectContinue && body != nil {
+		switch body.(type) {
+		case *bytes.Buffer, *bytes.Reader, *strings.Reader: // replayable by http.NewRequestWithContext
+		default:
+			raw, err := io.ReadAll(body)
+			if err != nil {
+				return nil, fmt.Errorf("unable to buffer body: %w", err)
+			}
+
+			body = bytes.NewReader(raw)
+		}
+	}
+
+	if b.label != "" {
+		ctx = context.WithValue(ctx, labelContextKey{}, b.label)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, b.method, b.url.String(), body)
 	if err != nil {
 		return nil, fmt.Errorf("unable to create request %s %s: %w", b.method, b.url.String(), err)
 	}
 
+	if b.bodySize != nil && body == b.body {
+		if err := setRequestSizedBody(req, body, *b.bodySize); err != nil {
+			return nil, err
+		}
+	}
+
 	for header, value := range b.header {
 		req.Header[header] = value
 	}
 
+	if b.dynamicHeaders != nil {
+		for header, value := range b.dynamicHeaders() {
+			header = textproto.CanonicalMIMEHeaderKey(header)
+			if _, exists := req.Header[header]; !exists {
+				req.Header[header] = value
+			}
+		}
+	}
+
+	for _, cookie := range b.cookies {
+		req.AddCookie(cookie)
+	}
+
 	if b.overrideFunc != nil {
 		if req, err = b.overrideFunc(req); err != nil {
 			return nil, fmt.Errorf("unable to override request: %w", err)
 		}
 	}
 
+	if b.uploadProgress != nil && req.Body != nil && req.Body != http.NoBody {
+		total := req.ContentLength
+		if total <= 0 {
+			total = -1
+		}
+		req.Body = &progressReadCloser{ReadCloser: req.Body, total: total, progress: b.uploadProgress}
+	}
+
 	return req, nil
 }
 
+// consumeBody marks the body as consumed by a built request. Seekable sized bodies are rewound to their
+// position when first consumed, other bodies can't be consumed twice.
+func (b *RequestBuilder) consumeBody() error {
+	seeker, isSeeker := b.body.(io.Seeker)
+	isSeeker = isSeeker && b.bodySize != nil
+
+	switch {
+	case !b.bodyConsumed && isSeeker:
+		offset, err := seeker.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return fmt.Errorf("unable to get body position: %v", err)
+		}
+		b.bodyOffset = offset
+	case b.bodyConsumed && isSeeker:
+		if _, err := seeker.Seek(b.bodyOffset, io.SeekStart); err != nil {
+			return fmt.Errorf("unable to rewind body: %v", err)
+		}
+	case b.bodyConsumed:
+		return errors.New("request body already consumed; use a replayable body or Clone the builder")
+	}
+
+	b.bodyConsumed = true
+	return nil
+}
+
+// Must is like Request but panics if the request can't be built.
+// It is meant to be used in tests and scripts, not in production code.
+func (b *RequestBuilder) Must(ctx context.Context) *http.Request {
+	req, err := b.Request(ctx)
+	if err != nil {
+		panic(err)
+	}
+	return req
+}
+
 // Do builds the request using Request(), executes it and returns a builder to handle the response.
 func (b *RequestBuilder) Do(ctx context.Context) *ResponseBuilder {
 	responseBuilder := newResponse()
+	if b.responseBodySizeReadLimit != nil {
+		responseBuilder.bodySizeReadLimit = *b.responseBodySizeReadLimit
+	}
+
+	ctx, cancel := b.context(ctx)
 
 	req, err := b.Request(ctx)
 	if err != nil {
+		cancel()
 		responseBuilder.builderError = fmt.Errorf("unable to create request: %w", err)
 		return responseBuilder
 	}
 
-	resp, err := b.client.Do(req)
+	resp, err := b.execute(req)
+	if err == nil && b.authRefreshOn != nil && b.authRefreshOn(resp) {
+		resp, err = b.retryAfterAuthRefresh(ctx, req, resp)
+	}
 	if err != nil {
-		responseBuilder.builderError = fmt.Errorf("unable to execute %s %s request: %w", req.Method, req.URL.String(), err)
+		cancel()
+		responseBuilder.builderError = err
 		return responseBuilder
 	}
 
+	if b.cancelOn != nil || b.timeout > 0 {
+		resp.Body = &cancelOnCloseReadCloser{ReadCloser: resp.Body, cancel: cancel}
+	}
+
 	responseBuilder.resp = resp
 	return responseBuilder
 }
+
+// context derives the provided context to apply the request timeout and cancellation channel, if set.
+// The returned cancel func must be called once the request is over.
+func (b *RequestBuilder) context(ctx context.Context) (context.Context, context.CancelFunc) {
+	cancel := context.CancelFunc(func() {})
+
+	if b.timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, b.timeout)
+	}
+
+	if b.cancelOn != nil {
+		cancelTimeout := cancel
+
+		var cancelCtx context.CancelFunc
+		ctx, cancelCtx = context.WithCancel(ctx)
+		cancel = func() { cancelCtx(); cancelTimeout() }
+
+		go func(ctx context.Context) {
+			select {
+			case <-b.cancelOn:
+				cancelCtx()
+			case <-ctx.Done():
+			}
+		}(ctx)
+	}
+
+	return ctx, cancel
+}
+
+// cancelOnCloseReadCloser calls cancel once the underlying ReadCloser is closed.
+type cancelOnCloseReadCloser struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (r *cancelOnCloseReadCloser) Close() error {
+	err := r.ReadCloser.Close()
+	r.cancel()
+	return err
+}
+
+// DoAndDecode executes the request, decodes the response body in JSON into the provided destination if the response status is 2xx,
+// and returns the response status for the caller to branch on. Other statuses are not considered an error,
+// and their body is not read. The body of 204 responses, and of responses with a zero content length, is not decoded.
+func (b *RequestBuilder) DoAndDecode(ctx context.Context, dest any) (int, error) {
+	var status int
+
+	responseBuilder := b.Do(ctx).
+		BeforeHandle(func(resp *http.Response) error {
+			status = resp.StatusCode
+			return nil
+		}).
+		OnStatusRange(100, 599, func(*http.Response) error { return nil }) //nolint:gomnd // any valid status
+
+	err := responseBuilder.OnStatusRange(200, 299, func(resp *http.Response) error { //nolint:gomnd // 2xx statuses
+		if resp.StatusCode == http.StatusNoContent || resp.ContentLength == 0 {
+			return nil
+		}
+		if err := json.NewDecoder(resp.Body).Decode(dest); err != nil {
+			return fmt.Errorf("%s: unable to parse JSON response body: %w", responseBuilder.formatResponseError(resp), err)
+		}
+		return nil
+	}).Error()
+
+	return status, err
+}
+
+// execute executes the provided request with the builder client.
+func (b *RequestBuilder) execute(req *http.Request) (*http.Response, error) {
+	client := b.client
+	if b.forceHTTP1 {
+		var err error
+		if client, err = clientWithHTTP1Only(client); err != nil {
+			return nil, err
+		}
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		if b.transportErrorWrapper != nil {
+			return nil, b.transportErrorWrapper(req.Method, req.URL.String(), err)
+		}
+		return nil, fmt.Errorf("unable to execute %s %s request: %w", req.Method, req.URL.String(), err)
+	}
+	return resp, nil
+}
+
+// retryAfterAuthRefresh closes the provided response, refreshes the authentication,
+// and executes the request once again. The request body, if any, is replayed using req.GetBody.
+func (b *RequestBuilder) retryAfterAuthRefresh(ctx context.Context, req *http.Request, resp *http.Response) (*http.Response, error) {
+	_ = resp.Body.Close()
+
+	if err := b.authRefresh(ctx); err != nil {
+		return nil, fmt.Errorf("unable to refresh authentication: %w", err)
+	}
+
+	if b.body != nil {
+		if req.GetBody == nil {
+			return nil, errors.New("unable to replay request body after authentication refresh: request GetBody is unset")
+		}
+
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, fmt.Errorf("unable to replay request body after authentication refresh: %w", err)
+		}
+
+		raw, err := io.ReadAll(body)
+		_ = body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("unable to replay request body after authentication refresh: %w", err)
+		}
+
+		b.body = bytes.NewReader(raw)
+		b.bodyConsumed = false
+	}
+
+	retriedReq, err := b.Request(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create request: %w", err)
+	}
+
+	return b.execute(retriedReq)
+}
@@ -1,15 +1,22 @@
 package httpclient
 
 import (
+	"bufio"
+	"bytes"
 	"context"
 	"encoding/json"
+	"encoding/xml"
 	"errors"
+	"fmt"
 	"io"
 	"net/http"
+	"net/http/cookiejar"
 	"net/http/httptest"
 	"net/url"
 	"strings"
 	"testing"
+	"testing/iotest"
+	"time"
 
 	"gotest.tools/v3/assert"
 	"gotest.tools/v3/assert/cmp"
@@ -26,6 +33,117 @@ func Test_RequestBuilder_Client(t *testing.T) {
 	assert.Check(t, req.client != nil)
 }
 
+func Test_RequestBuilder_Clone(t *testing.T) {
+	readBody := func(t *testing.T, requestBuilder *RequestBuilder) string {
+		req, err := requestBuilder.Request(context.Background())
+		assert.NilError(t, err)
+		raw, err := io.ReadAll(req.Body)
+		assert.NilError(t, err)
+		return string(raw)
+	}
+
+	t.Run("independent attributes", func(t *testing.T) {
+		original := NewRequest(http.MethodGet, "http://localhost/foo").
+			SetHeader("Hello", "world").
+			AddCookie(&http.Cookie{Name: "foo", Value: "bar"})
+		clone := original.Clone().
+			SetHeader("Hello", "you").
+			AddCookie(&http.Cookie{Name: "bar", Value: "baz"}).
+			SetQueryParam("q", "1")
+
+		assert.Check(t, original.header.Get("Hello") == "world")
+		assert.Check(t, len(original.cookies) == 1)
+		assert.Check(t, original.url.String() == "http://localhost/foo")
+		assert.Check(t, clone.header.Get("Hello") == "you")
+		assert.Check(t, len(clone.cookies) == 2)
+		assert.Check(t, clone.url.String() == "http://localhost/foo?q=1")
+	})
+
+	t.Run("streamed body", func(t *testing.T) {
+		original := NewRequest(http.MethodPost, "http://localhost").Send(io.MultiReader(strings.NewReader("hello")))
+		clone := original.Clone()
+
+		assert.Equal(t, readBody(t, original), "hello")
+		assert.Equal(t, readBody(t, clone), "hello")
+
+		_, err := original.Request(context.Background())
+		assert.Error(t, err, "request body already consumed; use a replayable body or Clone the builder")
+		_, err = original.Clone().Request(context.Background())
+		assert.Error(t, err, "request body already consumed; use a replayable body or Clone the builder")
+	})
+
+	t.Run("seekable sized body", func(t *testing.T) {
+		body := bytes.NewReader([]byte("-hello"))
+		_, err := body.Seek(1, io.SeekStart)
+		assert.NilError(t, err)
+
+		original := NewRequest(http.MethodPost, "http://localhost").SendSizedReader(body, 5, "")
+		clone := original.Clone()
+
+		assert.Equal(t, readBody(t, original), "hello")
+		assert.Equal(t, readBody(t, clone), "hello")
+		assert.Equal(t, readBody(t, original), "hello")
+	})
+
+	t.Run("unable to buffer body", func(t *testing.T) {
+		original := NewRequest(http.MethodPost, "http://localhost").Send(iotest.ErrReader(errors.New("boom")))
+		clone := original.Clone()
+
+		_, err := original.Request(context.Background())
+		assert.Error(t, err, "unable to buffer body: boom")
+		_, err = clone.Request(context.Background())
+		assert.Error(t, err, "unable to buffer body: boom")
+	})
+}
+
+func Test_RequestBuilder_BaseURL(t *testing.T) {
+	t.Run("without path", func(t *testing.T) {
+		httpServer, httpServerURL := newHTTPServerForTesting(t, func(rw http.ResponseWriter, r *http.Request) {
+			assert.Check(t, r.URL.Path == "/foo")
+			assert.Check(t, r.URL.Query().Get("bar") == "baz")
+			assert.Check(t, r.Header.Get("Hello") == "world")
+			rw.WriteHeader(http.StatusOK)
+		})
+
+		api := NewAPI(httpServer.Client(), url.URL{Scheme: "http", Host: "gateway.invalid"}).
+			WithRequestHeaders(http.Header{"Hello": {"world"}})
+
+		assert.NilError(t, api.Do(context.Background(),
+			api.Get("/foo").SetQueryParam("bar", "baz").BaseURL(httpServerURL),
+		).SuccessOnStatus(http.StatusOK).Error())
+	})
+
+	t.Run("with path", func(t *testing.T) {
+		req := NewRequest(http.MethodGet, "http://gateway.invalid/foo?bar=baz").
+			BaseURL(url.URL{Scheme: "https", User: url.User("user"), Host: "example.com", Path: "/api/"})
+		assert.Equal(t, req.url.String(), "https://user@example.com/api/foo?bar=baz")
+	})
+
+	t.Run("with escaped path", func(t *testing.T) {
+		httpServer, httpServerURL := newHTTPServerForTesting(t, func(rw http.ResponseWriter, r *http.Request) {
+			assert.Check(t, r.URL.EscapedPath() == "/api%20v1/users/a%2Fb")
+			rw.WriteHeader(http.StatusOK)
+		})
+		httpServerURL.Path = "/api v1"
+
+		req := NewRequest(http.MethodGet, "http://gateway.invalid/users/{id}").
+			PathReplacerEscaped("{id}", "a/b").
+			BaseURL(httpServerURL)
+		assert.Equal(t, req.url.String(), httpServer.URL+"/api%20v1/users/a%2Fb")
+		assert.NilError(t, req.Client(httpServer.Client()).Do(context.Background()).SuccessOnStatus(http.StatusOK).Error())
+	})
+}
+
+func Test_RequestBuilder_When(t *testing.T) {
+	debug := func(b *RequestBuilder) *RequestBuilder { return b.SetHeader("X-Debug", "1") }
+
+	req := NewRequest(http.MethodGet, "http://localhost").When(false, debug)
+	assert.Check(t, req.header.Get("X-Debug") == "")
+
+	req = req.When(true, debug)
+	assert.Check(t, req.header.Get("X-Debug") == "1")
+}
+
 func Test_RequestBuilder_SetHeader(t *testing.T) {
 	req := NewRequest(http.MethodGet, "http://localhost")
 	assert.Check(t, req.header != nil)
@@ -82,6 +200,86 @@ func Test_RequestBuilder_AddHeaders(t *testing.T) {
 	assert.DeepEqual(t, req.header, http.Header{"foobar": {"foo", "bar", "bar", "foo"}, "foo": {"bar"}, "bar": {"bar"}})
 }
 
+func Test_RequestBuilder_DelHeader(t *testing.T) {
+	req := NewRequest(http.MethodGet, "http://localhost").
+		SetHeaders(http.Header{"accept": {"text/plain"}, "foo": {"bar"}}).
+		AddHeader("Accept", "application/json")
+
+	req = req.DelHeader("ACCEPT")
+	assert.DeepEqual(t, req.header, http.Header{"foo": {"bar"}})
+
+	req = req.DelHeader("Accept")
+	assert.DeepEqual(t, req.header, http.Header{"foo": {"bar"}})
+}
+
+func Test_RequestBuilder_AddCookie(t *testing.T) {
+	req := NewRequest(http.MethodGet, "http://localhost").
+		SetHeader("Cookie", "session=42").
+		AddCookie(&http.Cookie{Name: "foo", Value: "bar"}).
+		AddCookie(&http.Cookie{Name: "hello", Value: "world"})
+
+	requestBuilt, err := req.Request(context.Background())
+	assert.NilError(t, err)
+	assert.Check(t, cmp.Equal(requestBuilt.Header.Get("Cookie"), "session=42; foo=bar; hello=world"))
+	assert.Check(t, cmp.DeepEqual(req.header, http.Header{"Cookie": {"session=42"}}))
+
+	cookie, err := requestBuilt.Cookie("hello")
+	assert.NilError(t, err)
+	assert.Check(t, cmp.Equal(cookie.Value, "world"))
+}
+
+func Test_RequestBuilder_CookiesFromJar(t *testing.T) {
+	jar, err := cookiejar.New(nil)
+	assert.NilError(t, err)
+	jar.SetCookies(&url.URL{Scheme: "http", Host: "localhost", Path: "/"}, []*http.Cookie{
+		{Name: "foo", Value: "bar"},
+		{Name: "hello", Value: "world", Path: "/api"},
+	})
+	jar.SetCookies(&url.URL{Scheme: "http", Host: "example.com"}, []*http.Cookie{{Name: "other", Value: "host"}})
+
+	requestBuilt, err := NewRequest(http.MethodGet, "http://localhost/api/users").
+		CookiesFromJar(jar).
+		Request(context.Background())
+	assert.NilError(t, err)
+	assert.Check(t, cmp.Equal(requestBuilt.Header.Get("Cookie"), "hello=world; foo=bar"))
+
+	requestBuilt, err = NewRequest(http.MethodGet, "http://localhost/").
+		CookiesFromJar(jar).
+		Request(context.Background())
+	assert.NilError(t, err)
+	assert.Check(t, cmp.Equal(requestBuilt.Header.Get("Cookie"), "foo=bar"))
+}
+
+func Test_RequestBuilder_SetBearerToken(t *testing.T) {
+	req := NewRequest(http.MethodGet, "http://localhost").AddHeader("authorization", "Basic Zm9vOmJhcg==")
+
+	req = req.SetBearerToken("foo")
+	assert.DeepEqual(t, req.header, http.Header{"Authorization": {"Bearer foo"}})
+
+	req = req.SetBearerToken("bar")
+	assert.DeepEqual(t, req.header, http.Header{"Authorization": {"Bearer bar"}})
+}
+
+func Test_RequestBuilder_ExpectsJSON(t *testing.T) {
+	req := NewRequest(http.MethodGet, "http://localhost")
+	assert.Check(t, req.header.Get("Accept") == "")
+
+	requestBuilt, err := req.ExpectsJSON().Request(context.Background())
+	assert.NilError(t, err)
+	assert.Check(t, cmp.DeepEqual(requestBuilt.Header["Accept"], []string{"application/json"}))
+}
+
+func Test_RequestBuilder_SetRawQuery(t *testing.T) {
+	req := NewRequest(http.MethodGet, "http://localhost?foo=bar").SetRawQuery("z=1&a=2&m=%20")
+
+	requestBuilt, err := req.Request(context.Background())
+	assert.NilError(t, err)
+	assert.Equal(t, requestBuilt.URL.String(), "http://localhost?z=1&a=2&m=%20")
+
+	req = req.SetQueryParam("b", "3")
+	assert.Equal(t, req.url.RawQuery, "a=2&b=3&m=+&z=1")
+}
+
 func Test_RequestBuilder_SetQueryParam(t *testing.T) {
 	req := NewRequest(http.MethodGet, "http://localhost")
 	assert.Check(t, req.url == url.URL{Scheme: "http", Host: "localhost"})
@@ -96,6 +294,18 @@ func Test_RequestBuilder_SetQueryParam(t *testing.T) {
 	assert.DeepEqual(t, req.url.Query(), url.Values{"foobar": {"bar", "foo"}, "foo": {"bar"}})
 }
 
+func Test_RequestBuilder_SetQueryParamIfNotEmpty(t *testing.T) {
+	req := NewRequest(http.MethodGet, "http://localhost?foo=bar")
+
+	req = req.SetQueryParamIfNotEmpty("foo", "")
+	req = req.SetQueryParamIfNotEmpty("bar", "")
+	assert.DeepEqual(t, req.url.Query(), url.Values{"foo": {"bar"}})
+
+	req = req.SetQueryParamIfNotEmpty("foo", "baz")
+	req = req.SetQueryParamIfNotEmpty("bar", "foo")
+	assert.DeepEqual(t, req.url.Query(), url.Values{"foo": {"baz"}, "bar": {"foo"}})
+}
+
 func Test_RequestBuilder_SetQueryParams(t *testing.T) {
 	req := NewRequest(http.MethodGet, "http://localhost")
 	assert.Check(t, req.url == url.URL{Scheme: "http", Host: "localhost"})
@@ -138,6 +348,76 @@ func Test_RequestBuilder_AddQueryParams(t *testing.T) {
 	assert.DeepEqual(t, req.url.Query(), url.Values{"foobar": {"foo", "bar", "bar", "foo"}, "foo": {"bar"}, "bar": {"bar"}})
 }
 
+func Test_RequestBuilder_DelQueryParam(t *testing.T) {
+	req := NewRequest(http.MethodGet, "http://localhost?z=1&foo=bar&foo=baz&a=2")
+
+	req = req.DelQueryParam("foo")
+	assert.Equal(t, req.url.RawQuery, "a=2&z=1")
+
+	req = req.DelQueryParam("foo")
+	assert.Equal(t, req.url.RawQuery, "a=2&z=1")
+}
+
+func Test_RequestBuilder_SetQueryParamsFromMap(t *testing.T) {
+	req := NewRequest(http.MethodGet, "http://localhost?foobar=foo&foobar=bar")
+
+	req = req.SetQueryParamsFromMap(map[string]string{"foo": "bar"})
+	assert.Equal(t, req.url.RawQuery, "foo=bar&foobar=foo&foobar=bar")
+
+	req = req.SetQueryParamsFromMap(map[string]string{"foobar": "foo bar", "bar": "foo"})
+	assert.Equal(t, req.url.RawQuery, "bar=foo&foo=bar&foobar=foo+bar")
+}
+
+func Test_RequestBuilder_AddQueryParamsFromMap(t *testing.T) {
+	req := NewRequest(http.MethodGet, "http://localhost?foobar=foo")
+
+	req = req.AddQueryParamsFromMap(map[string]string{"foo": "bar"})
+	assert.Equal(t, req.url.RawQuery, "foo=bar&foobar=foo")
+
+	req = req.AddQueryParamsFromMap(map[string]string{"foobar": "bar", "bar": "foo"})
+	assert.Equal(t, req.url.RawQuery, "bar=foo&foo=bar&foobar=foo&foobar=bar")
+}
+
+func Test_RequestBuilder_SetQueryParamsFromStruct(t *testing.T) {
+	req := NewRequest(http.MethodGet, "http://localhost?foobar=foo")
+
+	req = req.SetQueryParamsFromStruct(struct {
+		Foo string   `url:"foo"`
+		Bar []string `url:"foobar"`
+	}{Foo: "bar", Bar: []string{"bar", "baz"}})
+	assert.Equal(t, req.url.RawQuery, "foo=bar&foobar=bar&foobar=baz")
+
+	_, err := req.SetQueryParamsFromStruct(42).Request(context.Background())
+	assert.ErrorContains(t, err, "unable to encode query params: unable to encode int: expected a struct")
+}
+
+func Test_RequestBuilder_malformedQuery(t *testing.T) {
+	for name, setter := range map[string]func(*RequestBuilder) *RequestBuilder{
+		"SetQueryParam":  func(b *RequestBuilder) *RequestBuilder { return b.SetQueryParam("foo", "bar") },
+		"SetQueryParams": func(b *RequestBuilder) *RequestBuilder { return b.SetQueryParams(url.Values{"foo": {"bar"}}) },
+		"AddQueryParam":  func(b *RequestBuilder) *RequestBuilder { return b.AddQueryParam("foo", "bar") },
+		"AddQueryParams": func(b *RequestBuilder) *RequestBuilder { return b.AddQueryParams(url.Values{"foo": {"bar"}}) },
+	} {
+		setter := setter
+
+		t.Run(name, func(t *testing.T) {
+			req := setter(NewRequest(http.MethodGet, "http://localhost?a=%zz"))
+			requestBuilt, err := req.Request(context.Background())
+			assert.ErrorContains(t, err, `unable to parse url query "a=%zz"`)
+			assert.Check(t, requestBuilt == nil)
+		})
+	}
+
+	t.Run("only the first error is kept", func(t *testing.T) {
+		req := NewRequest(http.MethodGet, "http://localhost?a=%zz").SetQueryParam("foo", "bar")
+		req.url.RawQuery = "b=%yy"
+		req = req.SetQueryParam("foo", "bar")
+
+		_, err := req.Request(context.Background())
+		assert.ErrorContains(t, err, `unable to parse url query "a=%zz"`)
+	})
+}
+
 func Test_RequestBuilder_PathReplacer(t *testing.T) {
 	req := NewRequest(http.MethodGet, "http://localhost/localhost")
 	req = req.PathReplacer("localhost", "hostlocal")
@@ -156,6 +436,29 @@ func Test_RequestBuilder_PathReplacer(t *testing.T) {
 	assert.Equal(t, req.url.Path, "/42/22/{foobar}")
 }
 
+func Test_RequestBuilder_PathReplacerEscaped(t *testing.T) {
+	req := NewRequest(http.MethodGet, "http://localhost/users/{id}/{id}/{name}")
+	req = req.PathReplacerEscaped("{id}", "a/b").PathReplacerEscaped("{name}", "john doe")
+	assert.Equal(t, req.url.Path, "/users/a/b/a/b/john doe")
+
+	requestBuilt, err := req.Request(context.Background())
+	assert.NilError(t, err)
+	assert.Equal(t, requestBuilt.URL.String(), "http://localhost/users/a%2Fb/a%2Fb/john%20doe")
+
+	req = NewRequest(http.MethodGet, "http://localhost/users/{id}").PathReplacer("{id}", "a/b")
+	assert.Equal(t, req.url.String(), "http://localhost/users/a/b")
+}
+
+func Test_RequestBuilder_PathReplacerMap(t *testing.T) {
+	req := NewRequest(http.MethodGet, "http://localhost/orgs/{org}/repos/{repo}/issues/{n}/{org}")
+	req = req.PathReplacerMap(map[string]string{"{org}": "krostar", "{repo}": "httpclient", "{n}": "42", "{yolo}": "YOLO"})
+	assert.Equal(t, req.url.Path, "/orgs/krostar/repos/httpclient/issues/42/krostar")
+
+	req = NewRequest(http.MethodGet, "http://localhost/{a}/{b}")
+	req = req.PathReplacerMap(map[string]string{"{b}": "{a}", "{a}": "{b}"})
+	assert.Equal(t, req.url.Path, "/{a}/{a}")
+}
+
 func Test_RequestBuilder_SendForm(t *testing.T) {
 	req := NewRequest(http.MethodGet, "http://localhost")
 	assert.Check(t, req.bodyMarshaler == nil)
@@ -175,6 +478,63 @@ func Test_RequestBuilder_SendForm(t *testing.T) {
 	assert.Check(t, req.header.Get("Content-Type") == "application/x-www-form-urlencoded")
 }
 
+func Test_RequestBuilder_SendFormRaw(t *testing.T) {
+	req := NewRequest(http.MethodPost, "http://localhost").SendFormRaw("z=last%20first&a=b+c")
+	assert.Check(t, req.header.Get("Content-Type") == "application/x-www-form-urlencoded")
+
+	requestBuilt, err := req.Request(context.Background())
+	assert.NilError(t, err)
+	rawBody, err := io.ReadAll(requestBuilt.Body)
+	assert.NilError(t, err)
+	assert.Equal(t, string(rawBody), "z=last%20first&a=b+c")
+}
+
+func Test_RequestBuilder_SendFormFromStruct(t *testing.T) {
+	req := NewRequest(http.MethodPost, "http://localhost").SendFormFromStruct(struct {
+		Hello string `url:"hello"`
+	}{Hello: "world"})
+	assert.Check(t, req.body != nil)
+	rawQuery, err := io.ReadAll(req.body)
+	assert.NilError(t, err)
+	assert.Equal(t, string(rawQuery), "hello=world")
+	assert.Check(t, req.header.Get("Content-Type") == "application/x-www-form-urlencoded")
+
+	_, err = req.SendFormFromStruct("hello").Request(context.Background())
+	assert.ErrorContains(t, err, "unable to encode form: unable to encode string: expected a struct")
+}
+
+func Test_RequestBuilder_SendMarshaled(t *testing.T) {
+	marshaler := func(obj any) ([]byte, error) { return []byte(fmt.Sprintf("<%v>", obj)), nil }
+
+	t.Run("ok", func(t *testing.T) {
+		req := NewRequest(http.MethodPost, "http://localhost").SendMarshaled(42, marshaler, "application/x-custom")
+		assert.Check(t, req.bodyToMarshal == 42)
+		assert.Check(t, req.header.Get("Content-Type") == "application/x-custom")
+
+		requestBuilt, err := req.Request(context.Background())
+		assert.NilError(t, err)
+		rawBody, err := io.ReadAll(requestBuilt.Body)
+		assert.NilError(t, err)
+		assert.Check(t, cmp.Equal(string(rawBody), "<42>"))
+	})
+
+	t.Run("ko", func(t *testing.T) {
+		_, err := NewRequest(http.MethodPost, "http://localhost").
+			Send(strings.NewReader("hello")).
+			SendMarshaled(42, marshaler, "application/x-custom").
+			Request(context.Background())
+		assert.ErrorContains(t, err, "body to marshal is set but body is already set")
+
+		_, err = NewRequest(http.MethodPost, "http://localhost").SendMarshaled(42, nil, "application/x-custom").Request(context.Background())
+		assert.ErrorContains(t, err, "body to marshal is set but body marshaller is unset")
+
+		_, err = NewRequest(http.MethodPost, "http://localhost").
+			SendMarshaled(42, func(any) ([]byte, error) { return nil, errors.New("boom") }, "application/x-custom").
+			Request(context.Background())
+		assert.ErrorContains(t, err, "unable to marshal body: boom")
+	})
+}
+
 func Test_RequestBuilder_SendJSON(t *testing.T) {
 	req := NewRequest(http.MethodGet, "http://localhost")
 	assert.Check(t, req.bodyMarshaler == nil)
@@ -200,6 +560,112 @@ func Test_RequestBuilder_SendJSON(t *testing.T) {
 	assert.Check(t, req.header.Get("Content-Type") == "application/json")
 }
 
+func Test_RequestBuilder_SendXML(t *testing.T) {
+	type input struct {
+		XMLName struct{} `xml:"input"`
+		Say     string   `xml:"say"`
+		To      string   `xml:"to,attr"`
+	}
+
+	req := NewRequest(http.MethodPost, "http://localhost").SendXML(input{Say: "Hello", To: "world"})
+	assert.Check(t, req.body == nil)
+	assert.Check(t, req.header.Get("Content-Type") == "application/xml")
+
+	requestBuilt, err := req.Request(context.Background())
+	assert.NilError(t, err)
+	rawBody, err := io.ReadAll(requestBuilt.Body)
+	assert.NilError(t, err)
+	assert.Equal(t, string(rawBody), `<input to="world"><say>Hello</say></input>`)
+
+	_, err = NewRequest(http.MethodPost, "http://localhost").
+		Send(strings.NewReader("hello")).
+		SendXML(input{}).
+		Request(context.Background())
+	assert.Error(t, err, "body to marshal is set but body is already set")
+}
+
+func Test_RequestBuilder_SendJSONIndent(t *testing.T) {
+	req := NewRequest(http.MethodPost, "http://localhost").SendJSONIndent(map[string]any{"name": "foo", "tags": []string{"bar"}}, "", "  ")
+	assert.Check(t, req.body == nil)
+	assert.Check(t, req.header.Get("Content-Type") == "application/json")
+
+	requestBuilt, err := req.Request(context.Background())
+	assert.NilError(t, err)
+	rawBody, err := io.ReadAll(requestBuilt.Body)
+	assert.NilError(t, err)
+	assert.Check(t, cmp.Equal(string(rawBody), "{\n  \"name\": \"foo\",\n  \"tags\": [\n    \"bar\"\n  ]\n}"))
+}
+
+func Test_RequestBuilder_SendJSONPatch(t *testing.T) {
+	req := NewRequest(http.MethodPatch, "http://localhost").SendJSONPatch([]JSONPatchOp{
+		{Op: "add", Path: "/tags/-", Value: "new"},
+		{Op: "replace", Path: "/name", Value: "foo"},
+		{Op: "remove", Path: "/email"},
+		{Op: "test", Path: "/deleted_at", Value: nil},
+		{Op: "move", From: "/old", Path: "/new"},
+	})
+	assert.Check(t, req.body == nil)
+	assert.Check(t, req.header.Get("Content-Type") == "application/json-patch+json")
+
+	requestBuilt, err := req.Request(context.Background())
+	assert.NilError(t, err)
+	rawBody, err := io.ReadAll(requestBuilt.Body)
+	assert.NilError(t, err)
+	assert.Check(t, cmp.Equal(string(rawBody),
+		`[{"op":"add","path":"/tags/-","value":"new"},{"op":"replace","path":"/name","value":"foo"},{"op":"remove","path":"/email"},`+
+			`{"op":"test","path":"/deleted_at","value":null},{"op":"move","path":"/new","from":"/old"}]`,
+	))
+}
+
+func Test_RequestBuilder_SendJSONMergePatch(t *testing.T) {
+	req := NewRequest(http.MethodPatch, "http://localhost").SendJSONMergePatch(map[string]any{"name": "foo", "email": nil})
+	assert.Check(t, req.body == nil)
+	assert.Check(t, req.header.Get("Content-Type") == "application/merge-patch+json")
+
+	requestBuilt, err := req.Request(context.Background())
+	assert.NilError(t, err)
+	rawBody, err := io.ReadAll(requestBuilt.Body)
+	assert.NilError(t, err)
+	assert.Check(t, cmp.Equal(string(rawBody), `{"email":null,"name":"foo"}`))
+}
+
+func Test_RequestBuilder_SendJSONLines(t *testing.T) {
+	type input struct {
+		ID int `json:"id"`
+	}
+
+	t.Run("ok", func(t *testing.T) {
+		var lines []string
+
+		httpServer, httpServerURL := newHTTPServerForTesting(t, func(rw http.ResponseWriter, r *http.Request) {
+			assert.Check(t, r.Header.Get("Content-Type") == "application/x-ndjson")
+			scanner := bufio.NewScanner(r.Body)
+			for scanner.Scan() {
+				lines = append(lines, scanner.Text())
+			}
+			assert.NilError(t, scanner.Err())
+			rw.WriteHeader(http.StatusNoContent)
+		})
+
+		assert.NilError(t, NewRequest(http.MethodPost, httpServerURL.String()).
+			Client(httpServer.Client()).
+			SendJSONLines([]any{input{ID: 1}, input{ID: 2}, input{ID: 3}}).
+			Do(context.Background()).
+			SuccessOnStatus(http.StatusNoContent).
+			Error(),
+		)
+		assert.Check(t, cmp.DeepEqual(lines, []string{`{"id":1}`, `{"id":2}`, `{"id":3}`}))
+	})
+
+	t.Run("ko", func(t *testing.T) {
+		requestBuilt, err := NewRequest(http.MethodPost, "http://localhost").
+			SendJSONLines([]any{input{ID: 1}, make(chan int)}).
+			Request(context.Background())
+		assert.ErrorContains(t, err, "unable to marshal body: unable to marshal item 1")
+		assert.Check(t, requestBuilt == nil)
+	})
+}
+
 func Test_RequestBuilder_Send(t *testing.T) {
 	req := NewRequest(http.MethodGet, "http://localhost")
 	assert.Check(t, req.bodyMarshaler == nil)
@@ -217,6 +683,232 @@ func Test_RequestBuilder_Send(t *testing.T) {
 	assert.Check(t, req.header.Get("Content-Type") == "application/octet-stream")
 }
 
+func Test_RequestBuilder_SendWithContentType(t *testing.T) {
+	req := NewRequest(http.MethodPost, "http://localhost").SendWithContentType(strings.NewReader(`{"data":{}}`), "application/vnd.api+json")
+	assert.Check(t, req.header.Get("Content-Type") == "application/vnd.api+json")
+	rawBody, err := io.ReadAll(req.body)
+	assert.NilError(t, err)
+	assert.Check(t, cmp.Equal(string(rawBody), `{"data":{}}`))
+
+	req = req.SetHeader("Content-Type", "application/json")
+	requestBuilt, err := req.Request(context.Background())
+	assert.NilError(t, err)
+	assert.Check(t, requestBuilt.Header.Get("Content-Type") == "application/json")
+}
+
+func Test_RequestBuilder_SendText(t *testing.T) {
+	req := NewRequest(http.MethodPost, "http://localhost").SendText("hello world!")
+	assert.Check(t, req.header.Get("Content-Type") == "text/plain; charset=utf-8")
+
+	requestBuilt, err := req.Request(context.Background())
+	assert.NilError(t, err)
+	assert.Check(t, requestBuilt.ContentLength == 12)
+	rawBody, err := io.ReadAll(requestBuilt.Body)
+	assert.NilError(t, err)
+	assert.Check(t, cmp.Equal(string(rawBody), "hello world!"))
+}
+
+func Test_RequestBuilder_SendSizedReader(t *testing.T) {
+	readAll := func(t *testing.T, body io.ReadCloser) string {
+		raw, err := io.ReadAll(body)
+		assert.NilError(t, err)
+		assert.NilError(t, body.Close())
+		return string(raw)
+	}
+
+	t.Run("not seekable", func(t *testing.T) {
+		req, err := NewRequest(http.MethodPost, "http://localhost").
+			SendSizedReader(io.MultiReader(strings.NewReader("hello")), 5, "text/plain").
+			Request(context.Background())
+		assert.NilError(t, err)
+		assert.Equal(t, req.ContentLength, int64(5))
+		assert.Equal(t, req.Header.Get("Content-Type"), "text/plain")
+		assert.Check(t, req.GetBody == nil)
+		assert.Equal(t, readAll(t, req.Body), "hello")
+	})
+
+	t.Run("seekable", func(t *testing.T) {
+		for name, body := range map[string]io.ReadSeeker{
+			"bytes reader": bytes.NewReader([]byte("-hello")),
+			"seeker":       struct{ io.ReadSeeker }{bytes.NewReader([]byte("-hello"))},
+		} {
+			body := body
+
+			t.Run(name, func(t *testing.T) {
+				_, err := body.Seek(1, io.SeekStart)
+				assert.NilError(t, err)
+
+				req, err := NewRequest(http.MethodPost, "http://localhost").
+					SendSizedReader(body, 5, "").
+					Request(context.Background())
+				assert.NilError(t, err)
+				assert.Equal(t, req.ContentLength, int64(5))
+				assert.Equal(t, req.Header.Get("Content-Type"), "")
+				assert.Equal(t, readAll(t, req.Body), "hello")
+
+				assert.Assert(t, req.GetBody != nil)
+				replayed, err := req.GetBody()
+				assert.NilError(t, err)
+				assert.Equal(t, readAll(t, replayed), "hello")
+			})
+		}
+	})
+
+	t.Run("seekable, built twice", func(t *testing.T) {
+		body := bytes.NewReader([]byte("-hello"))
+		_, err := body.Seek(1, io.SeekStart)
+		assert.NilError(t, err)
+
+		requestBuilder := NewRequest(http.MethodPost, "http://localhost").SendSizedReader(body, 5, "")
+		for i := 0; i < 2; i++ {
+			req, err := requestBuilder.Request(context.Background())
+			assert.NilError(t, err)
+			assert.Equal(t, readAll(t, req.Body), "hello")
+		}
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		req, err := NewRequest(http.MethodPost, "http://localhost").
+			SendSizedReader(io.MultiReader(), 0, "").
+			Request(context.Background())
+		assert.NilError(t, err)
+		assert.Equal(t, req.ContentLength, int64(0))
+		assert.Check(t, req.Body == http.NoBody)
+	})
+}
+
+func Test_RequestBuilder_Expect100Continue(t *testing.T) {
+	t.Run("ok", func(t *testing.T) {
+		req := NewRequest(http.MethodPost, "http://localhost")
+		assert.Check(t, !req.expectContinue)
+
+		req = req.Send(io.MultiReader(strings.NewReader("hello world!"))).Expect100Continue()
+		assert.Check(t, req.expectContinue)
+		assert.Check(t, req.header.Get("Expect") == "100-continue")
+
+		requestBuilt, err := req.Request(context.Background())
+		assert.NilError(t, err)
+		assert.Check(t, requestBuilt.Header.Get("Expect") == "100-continue")
+		assert.Assert(t, requestBuilt.GetBody != nil)
+
+		body, err := requestBuilt.GetBody()
+		assert.NilError(t, err)
+		rawBody, err := io.ReadAll(body)
+		assert.NilError(t, err)
+		assert.Check(t, cmp.Equal(string(rawBody), "hello world!"))
+	})
+
+	t.Run("ko", func(t *testing.T) {
+		req := NewRequest(http.MethodPost, "http://localhost").
+			Send(iotest.ErrReader(errors.New("boom"))).
+			Expect100Continue()
+
+		requestBuilt, err := req.Request(context.Background())
+		assert.ErrorContains(t, err, "unable to buffer body: boom")
+		assert.Check(t, requestBuilt == nil)
+	})
+}
+
+func Test_RequestBuilder_ForceHTTP1(t *testing.T) {
+	httpServer := httptest.NewUnstartedServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		_, _ = rw.Write([]byte(r.Proto))
+	}))
+	httpServer.EnableHTTP2 = true
+	httpServer.StartTLS()
+	t.Cleanup(httpServer.Close)
+
+	t.Run("ok", func(t *testing.T) {
+		for name, test := range map[string]struct {
+			forceHTTP1    bool
+			expectedProto string
+		}{
+			"default":     {expectedProto: "HTTP/2.0"},
+			"force http1": {forceHTTP1: true, expectedProto: "HTTP/1.1"},
+		} {
+			test := test
+			t.Run(name, func(t *testing.T) {
+				var proto string
+
+				req := NewRequest(http.MethodGet, httpServer.URL).Client(httpServer.Client())
+				if test.forceHTTP1 {
+					req = req.ForceHTTP1()
+				}
+
+				assert.NilError(t, req.
+					Do(context.Background()).
+					BodySizeReadLimit(KB).
+					ReceiveString(http.StatusOK, &proto, nil).
+					Error(),
+				)
+				assert.Check(t, cmp.Equal(proto, test.expectedProto))
+			})
+		}
+	})
+
+	t.Run("ko", func(t *testing.T) {
+		err := NewRequest(http.MethodGet, httpServer.URL).
+			Client(&doerFail{}).
+			ForceHTTP1().
+			Do(context.Background()).
+			Error()
+		assert.ErrorContains(t, err, "unable to force HTTP/1.1: client *httpclient.doerFail is not an *http.Client")
+
+		err = NewRequest(http.MethodGet, httpServer.URL).
+			Client(&http.Client{Transport: http.NewFileTransport(http.Dir("."))}).
+			ForceHTTP1().
+			Do(context.Background()).
+			Error()
+		assert.ErrorContains(t, err, "unable to force HTTP/1.1: client transport http.fileTransport is not an *http.Transport")
+	})
+}
+
+func Test_RequestBuilder_OnUploadProgress(t *testing.T) {
+	body := strings.Repeat("a", 1<<20)
+
+	httpServer, httpServerURL := newHTTPServerForTesting(t, func(rw http.ResponseWriter, r *http.Request) {
+		received, err := io.ReadAll(r.Body)
+		assert.NilError(t, err)
+		assert.Check(t, len(received) == len(body))
+		rw.WriteHeader(http.StatusOK)
+	})
+
+	t.Run("known size", func(t *testing.T) {
+		var progress [][2]int64
+
+		assert.NilError(t, NewRequest(http.MethodPost, httpServerURL.String()).
+			Client(httpServer.Client()).
+			Send(strings.NewReader(body)).
+			OnUploadProgress(func(bytesSent, total int64) {
+				progress = append(progress, [2]int64{bytesSent, total})
+			}).
+			Do(context.Background()).
+			SuccessOnStatus(http.StatusOK).
+			Error(),
+		)
+
+		assert.Assert(t, len(progress) > 1)
+		for i := 1; i < len(progress); i++ {
+			assert.Check(t, progress[i][0] > progress[i-1][0])
+		}
+		assert.Check(t, cmp.DeepEqual(progress[len(progress)-1], [2]int64{int64(len(body)), int64(len(body))}))
+	})
+
+	t.Run("unknown size", func(t *testing.T) {
+		var lastSent, lastTotal int64
+
+		assert.NilError(t, NewRequest(http.MethodPost, httpServerURL.String()).
+			Client(httpServer.Client()).
+			Send(io.MultiReader(strings.NewReader(body))).
+			OnUploadProgress(func(bytesSent, total int64) { lastSent, lastTotal = bytesSent, total }).
+			Do(context.Background()).
+			SuccessOnStatus(http.StatusOK).
+			Error(),
+		)
+		assert.Check(t, lastSent == int64(len(body)))
+		assert.Check(t, lastTotal == -1)
+	})
+}
+
 func Test_RequestBuilder_SetOverrideFunc(t *testing.T) {
 	req := NewRequest(http.MethodGet, "http://localhost/localhost")
 	assert.Check(t, req.overrideFunc == nil)
@@ -226,6 +918,147 @@ func Test_RequestBuilder_SetOverrideFunc(t *testing.T) {
 	assert.Check(t, req.overrideFunc != nil)
 }
 
+func Test_RequestBuilder_WithoutOverride(t *testing.T) {
+	var overridden int
+
+	api := NewAPI(http.DefaultClient, url.URL{Scheme: "http", Host: "localhost"}).
+		WithRequestOverrideFunc(func(req *http.Request) (*http.Request, error) {
+			overridden++
+			req.Header.Set("X-Signature", "signed")
+			return req, nil
+		})
+
+	requestBuilt, err := api.Get("/foo").Request(context.Background())
+	assert.NilError(t, err)
+	assert.Check(t, overridden == 1)
+	assert.Check(t, requestBuilt.Header.Get("X-Signature") == "signed")
+
+	requestBuilt, err = api.Get("/foo").WithoutOverride().Request(context.Background())
+	assert.NilError(t, err)
+	assert.Check(t, overridden == 1)
+	assert.Check(t, requestBuilt.Header.Get("X-Signature") == "")
+}
+
+type labelReaderDoer struct {
+	doer   Doer
+	labels []string
+}
+
+func (d *labelReaderDoer) Do(req *http.Request) (*http.Response, error) {
+	if label, found := LabelFromContext(req.Context()); found {
+		d.labels = append(d.labels, label)
+	}
+	return d.doer.Do(req)
+}
+
+func Test_RequestBuilder_Label(t *testing.T) {
+	httpServer, httpServerURL := newHTTPServerForTesting(t, func(rw http.ResponseWriter, r *http.Request) {
+		rw.WriteHeader(http.StatusOK)
+	})
+
+	doer := &labelReaderDoer{doer: httpServer.Client()}
+
+	assert.NilError(t, NewRequest(http.MethodPost, httpServerURL.String()).
+		Client(doer).
+		Label("CreateUser").
+		Do(context.Background()).
+		SuccessOnStatus(http.StatusOK).
+		Error(),
+	)
+
+	assert.NilError(t, NewRequest(http.MethodGet, httpServerURL.String()).
+		Client(doer).
+		Do(context.Background()).
+		SuccessOnStatus(http.StatusOK).
+		Error(),
+	)
+
+	assert.DeepEqual(t, doer.labels, []string{"CreateUser"})
+
+	_, found := LabelFromContext(context.Background())
+	assert.Check(t, !found)
+}
+
+func Test_RequestBuilder_CancelOn(t *testing.T) {
+	httpServer, httpServerURL := newHTTPServerForTesting(t, func(rw http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("slow") != "" {
+			<-r.Context().Done()
+			return
+		}
+		rw.WriteHeader(http.StatusOK)
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		assert.NilError(t, NewRequest(http.MethodGet, httpServerURL.String()).
+			Client(httpServer.Client()).
+			CancelOn(make(chan struct{})).
+			Do(context.Background()).
+			SuccessOnStatus(http.StatusOK).
+			Error(),
+		)
+	})
+
+	t.Run("ko", func(t *testing.T) {
+		cancel := make(chan struct{})
+		time.AfterFunc(50*time.Millisecond, func() { close(cancel) })
+
+		err := NewRequest(http.MethodGet, httpServerURL.String()+"?slow=1").
+			Client(httpServer.Client()).
+			CancelOn(cancel).
+			Do(context.Background()).
+			SuccessOnStatus(http.StatusOK).
+			Error()
+		assert.Check(t, errors.Is(err, context.Canceled))
+	})
+}
+
+func Test_RequestBuilder_Timeout(t *testing.T) {
+	httpServer, httpServerURL := newHTTPServerForTesting(t, func(rw http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("slow") != "" {
+			<-r.Context().Done()
+			return
+		}
+		rw.WriteHeader(http.StatusOK)
+		_, _ = rw.Write([]byte("hello"))
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		var body string
+		assert.NilError(t, NewRequest(http.MethodGet, httpServerURL.String()).
+			Client(httpServer.Client()).
+			Timeout(time.Second).
+			Do(context.Background()).
+			BodySizeReadLimit(KB).
+			ReceiveString(http.StatusOK, &body, nil).
+			Error(),
+		)
+		assert.Check(t, cmp.Equal(body, "hello"))
+	})
+
+	t.Run("ko", func(t *testing.T) {
+		err := NewRequest(http.MethodGet, httpServerURL.String()+"?slow=1").
+			Client(httpServer.Client()).
+			Timeout(50 * time.Millisecond).
+			CancelOn(make(chan struct{})).
+			Do(context.Background()).
+			SuccessOnStatus(http.StatusOK).
+			Error()
+		assert.Check(t, errors.Is(err, context.DeadlineExceeded))
+	})
+}
+
+func Test_RequestBuilder_ResponseBodySizeReadLimit(t *testing.T) {
+	req := NewRequest(http.MethodGet, "http://localhost")
+	assert.Check(t, req.responseBodySizeReadLimit == nil)
+
+	req = req.ResponseBodySizeReadLimit(42)
+	assert.Assert(t, req.responseBodySizeReadLimit != nil)
+	assert.Check(t, *req.responseBodySizeReadLimit == 42)
+
+	resp := req.Client(&doerFail{err: errors.New("boom")}).Do(context.Background())
+	assert.Check(t, resp.bodySizeReadLimit == 42)
+}
+
 func Test_RequestBuilder_Request(t *testing.T) {
 	type ctxKey string
 
@@ -265,6 +1098,23 @@ func Test_RequestBuilder_Request(t *testing.T) {
 				))
 			})
 
+			t.Run("need to serialize, built twice", func(t *testing.T) {
+				requestBuilder := NewRequest(http.MethodPost, "http://localhost")
+				requestBuilder = requestBuilder.SendJSON("42")
+
+				for i := 0; i < 2; i++ {
+					requestBuilt, err := requestBuilder.Request(context.Background())
+					assert.NilError(t, err)
+					assert.Check(t, compareHTTPRequestFunc(requestBuilt,
+						newHTTPRequestForTesting(t, http.MethodPost, "http://localhost", strings.NewReader(`"42"`),
+							func(t *testing.T, request *http.Request) {
+								request.Header.Add("Content-Type", "application/json")
+							},
+						),
+					))
+				}
+			})
+
 			t.Run("no need to serialize", func(t *testing.T) {
 				requestBuilder := NewRequest(http.MethodPost, "http://localhost")
 				requestBuilder = requestBuilder.Send(strings.NewReader("hello world"))
@@ -300,6 +1150,20 @@ func Test_RequestBuilder_Request(t *testing.T) {
 				assert.Check(t, requestBuilt == nil)
 			})
 
+			t.Run("body already consumed", func(t *testing.T) {
+				requestBuilder := NewRequest(http.MethodPost, "http://localhost").Send(strings.NewReader("hello world"))
+				_, err := requestBuilder.Request(context.Background())
+				assert.NilError(t, err)
+
+				requestBuilt, err := requestBuilder.Request(context.Background())
+				assert.Error(t, err, "request body already consumed; use a replayable body or Clone the builder")
+				assert.Check(t, requestBuilt == nil)
+
+				requestBuilder = requestBuilder.Send(strings.NewReader("hello world"))
+				_, err = requestBuilder.Request(context.Background())
+				assert.NilError(t, err)
+			})
+
 			t.Run("without body serializer", func(t *testing.T) {
 				requestBuilder := NewRequest(http.MethodPost, "http://localhost")
 				requestBuilder.bodyToMarshal = `"hello world"`
@@ -342,6 +1206,139 @@ func Test_RequestBuilder_Request(t *testing.T) {
 	})
 }
 
+func Test_RequestBuilder_Request_replayableBody(t *testing.T) {
+	t.Run("get body", func(t *testing.T) {
+		for name, test := range map[string]struct {
+			req          *RequestBuilder
+			expectedBody string
+		}{
+			"json":          {req: NewRequest(http.MethodPost, "http://localhost").SendJSON("hello"), expectedBody: `"hello"`},
+			"form":          {req: NewRequest(http.MethodPost, "http://localhost").SendForm(url.Values{"foo": {"bar"}}), expectedBody: "foo=bar"},
+			"text":          {req: NewRequest(http.MethodPost, "http://localhost").SendText("hello"), expectedBody: "hello"},
+			"bytes buffer":  {req: NewRequest(http.MethodPost, "http://localhost").Send(bytes.NewBufferString("hello")), expectedBody: "hello"},
+			"bytes reader":  {req: NewRequest(http.MethodPost, "http://localhost").Send(bytes.NewReader([]byte("hello"))), expectedBody: "hello"},
+			"sized seeker":  {req: NewRequest(http.MethodPost, "http://localhost").SendSizedReader(strings.NewReader("hello"), 5, ""), expectedBody: "hello"},
+			"sized, empty":  {req: NewRequest(http.MethodPost, "http://localhost").SendSizedReader(io.MultiReader(), 0, ""), expectedBody: ""},
+			"marshaled xml": {req: NewRequest(http.MethodPost, "http://localhost").SendXML(struct{ XMLName xml.Name }{XMLName: xml.Name{Local: "a"}}), expectedBody: "<a></a>"},
+		} {
+			test := test
+			t.Run(name, func(t *testing.T) {
+				requestBuilt, err := test.req.Request(context.Background())
+				assert.NilError(t, err)
+				assert.Assert(t, requestBuilt.GetBody != nil)
+
+				for i := 0; i < 2; i++ {
+					body, err := requestBuilt.GetBody()
+					assert.NilError(t, err)
+					raw, err := io.ReadAll(body)
+					assert.NilError(t, err)
+					assert.Check(t, cmp.Equal(string(raw), test.expectedBody))
+				}
+			})
+		}
+
+		requestBuilt, err := NewRequest(http.MethodPost, "http://localhost").Send(io.MultiReader(strings.NewReader("hello"))).Request(context.Background())
+		assert.NilError(t, err)
+		assert.Check(t, requestBuilt.GetBody == nil)
+	})
+
+	t.Run("307 redirect", func(t *testing.T) {
+		httpServer, httpServerURL := newHTTPServerForTesting(t, func(rw http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/redirect" {
+				http.Redirect(rw, r, "/target", http.StatusTemporaryRedirect)
+				return
+			}
+
+			body, err := io.ReadAll(r.Body)
+			assert.Check(t, err)
+			assert.Check(t, cmp.Equal(string(body), `{"hello":"world"}`))
+			rw.WriteHeader(http.StatusOK)
+		})
+
+		assert.NilError(t, NewRequest(http.MethodPost, httpServerURL.String()+"/redirect").
+			Client(httpServer.Client()).
+			SendJSON(map[string]string{"hello": "world"}).
+			Do(context.Background()).
+			SuccessOnStatus(http.StatusOK).
+			Error(),
+		)
+	})
+}
+
+func Test_RequestBuilder_DoAndDecode(t *testing.T) {
+	httpServer, httpServerURL := newHTTPServerForTesting(t, func(rw http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/ok":
+			rw.WriteHeader(http.StatusOK)
+			_, _ = rw.Write([]byte(`{"hello":"world"}`))
+		case "/no-content":
+			rw.WriteHeader(http.StatusNoContent)
+		case "/created":
+			rw.WriteHeader(http.StatusCreated)
+		case "/invalid":
+			rw.WriteHeader(http.StatusOK)
+			_, _ = rw.Write([]byte(`{`))
+		default:
+			rw.WriteHeader(http.StatusNotFound)
+			_, _ = rw.Write([]byte(`not found`))
+		}
+	})
+
+	doAndDecode := func(endpoint string) (int, map[string]string, error) {
+		var body map[string]string
+		status, err := NewRequest(http.MethodGet, httpServerURL.String()+endpoint).
+			Client(httpServer.Client()).
+			DoAndDecode(context.Background(), &body)
+		return status, body, err
+	}
+
+	t.Run("ok", func(t *testing.T) {
+		status, body, err := doAndDecode("/ok")
+		assert.NilError(t, err)
+		assert.Check(t, status == http.StatusOK)
+		assert.Check(t, cmp.DeepEqual(body, map[string]string{"hello": "world"}))
+
+		status, body, err = doAndDecode("/no-content")
+		assert.NilError(t, err)
+		assert.Check(t, status == http.StatusNoContent)
+		assert.Check(t, body == nil)
+
+		status, body, err = doAndDecode("/created")
+		assert.NilError(t, err)
+		assert.Check(t, status == http.StatusCreated)
+		assert.Check(t, body == nil)
+
+		status, body, err = doAndDecode("/not-found")
+		assert.NilError(t, err)
+		assert.Check(t, status == http.StatusNotFound)
+		assert.Check(t, body == nil)
+	})
+
+	t.Run("ko", func(t *testing.T) {
+		status, _, err := doAndDecode("/invalid")
+		assert.ErrorContains(t, err, "unable to parse JSON response body")
+		assert.Check(t, status == http.StatusOK)
+
+		status, err = NewRequest(http.MethodGet, httpServerURL.String()).
+			Client(&doerFail{err: errors.New("boom")}).
+			DoAndDecode(context.Background(), new(map[string]string))
+		assert.ErrorContains(t, err, "boom")
+		assert.Check(t, status == 0)
+	})
+}
+
+func Test_RequestBuilder_Must(t *testing.T) {
+	req := NewRequest(http.MethodGet, "http://localhost").Must(context.Background())
+	assert.Equal(t, req.URL.String(), "http://localhost")
+
+	defer func() {
+		err, isErr := recover().(error)
+		assert.Assert(t, isErr)
+		assert.ErrorContains(t, err, "unable to parse endpoint url")
+	}()
+	NewRequest(http.MethodGet, "%zz").Must(context.Background())
+}
+
 func Test_RequestBuilder_Do(t *testing.T) {
 	t.Run("ok", func(t *testing.T) {
 		httpServer := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
@@ -367,6 +1364,22 @@ func Test_RequestBuilder_Do(t *testing.T) {
 	})
 
 	t.Run("ko", func(t *testing.T) {
+		t.Run("body already consumed by a previous call", func(t *testing.T) {
+			httpServer, httpServerURL := newHTTPServerForTesting(t, func(rw http.ResponseWriter, r *http.Request) {
+				rw.WriteHeader(http.StatusTeapot)
+			})
+
+			requestBuilder := NewRequest(http.MethodPost, httpServerURL.String()).
+				Client(httpServer.Client()).
+				Send(strings.NewReader("hello world"))
+
+			assert.NilError(t, requestBuilder.Do(context.Background()).SuccessOnStatus(http.StatusTeapot).Error())
+			assert.ErrorContains(t,
+				requestBuilder.Do(context.Background()).SuccessOnStatus(http.StatusTeapot).Error(),
+				"unable to create request: request body already consumed; use a replayable body or Clone the builder",
+			)
+		})
+
 		t.Run("unable to create the request", func(t *testing.T) {
 			responseBuilder := NewRequest(`\`, "http://localhost").Do(context.Background())
 			assert.Assert(t, responseBuilder != nil)
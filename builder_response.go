@@ -1,11 +1,24 @@
 package httpclient
 
 import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
 	"encoding/base64"
 	"encoding/json"
+	"encoding/xml"
+	"errors"
 	"fmt"
 	"io"
+	"mime"
+	"mime/multipart"
 	"net/http"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/exp/slices"
 )
 
 type (
@@ -15,16 +28,51 @@ type (
 	// ResponseHandler defines the signature of the function called to handle a response.
 	ResponseHandler func(*http.Response) error
 
+	// CharsetReaderFunc defines the signature of the function called to convert
+	// an input encoded with the provided charset to an UTF-8 reader.
+	CharsetReaderFunc func(charset string, input io.Reader) (io.Reader, error)
+
 	// ResponseBuilder stores the different attributes set by the builder methods.
 	ResponseBuilder struct {
 		builderError error
 
-		resp              *http.Response
-		bodySizeReadLimit int64
-		statusHandler     ResponseStatusHandlers
+		resp                *http.Response
+		bodySizeReadLimit   int64
+		bodySizeStrict      bool
+		statusHandler       ResponseStatusHandlers
+		statusRanges        []statusRangeHandler
+		bodyLimited         bool
+		bodyBuffered        bool
+		bytesRead           int64
+		downloadProgress    func(bytesRead, total int64)
+		jsonErrorCode       func(resp *http.Response, raw []byte) error
+		verifyContentLength bool
+		readTimeout         time.Duration
+		decompressSniff     bool
+		decodeCompressed    bool
+		statusHandled       bool
+		beforeHandle        []ResponseHandler
+		headerBoundStatuses map[int]struct{}
+		bufferedBody        []byte
 	}
 )
 
+// statusRangeHandler is a handler called for statuses between from and to, included.
+type statusRangeHandler struct {
+	from, to int
+	handler  ResponseHandler
+}
+
+// Sizes helpers, to be used with body size read limits, like api.WithResponseBodySizeReadLimit(10 * httpclient.MB).
+const (
+	KB = 1 << 10
+	MB = 1 << 20
+)
+
+// DecompressedBodySizeReadLimit is the maximum size of a decompressed response body, see DecodeCompressed,
+// when no body size read limit is set. It guards against decompression bombs.
+const DecompressedBodySizeReadLimit = 32 * MB
+
 func newResponse() *ResponseBuilder {
 	return &ResponseBuilder{statusHandler: make(ResponseStatusHandlers)}
 }
@@ -35,6 +83,73 @@ func newResponse() *ResponseBuilder {
 // Negative value disables checks and limitations.
 func (b *ResponseBuilder) BodySizeReadLimit(bodySizeReadLimit int64) *ResponseBuilder {
 	b.bodySizeReadLimit = bodySizeReadLimit
+	b.bodySizeStrict = false
+	return b
+}
+
+// BodySizeReadLimitStrict limits the maximum amount of octets to be read in the response, like BodySizeReadLimit,
+// but instead of silently truncating the body when the content-length is unknown, reading more than the limit fails.
+func (b *ResponseBuilder) BodySizeReadLimitStrict(bodySizeReadLimit int64) *ResponseBuilder {
+	b.bodySizeReadLimit = bodySizeReadLimit
+	b.bodySizeStrict = true
+	return b
+}
+
+// OnDownloadProgress sets a callback called each time a part of the response body is read.
+// The callback receives the amount of bytes read so far, and the total size of the body, or -1 if unknown.
+func (b *ResponseBuilder) OnDownloadProgress(fn func(bytesRead, total int64)) *ResponseBuilder {
+	b.downloadProgress = fn
+	return b
+}
+
+// BufferBody reads the whole response body, bounded by the body size read limit, in memory before handling the response.
+// The body can then be read multiple times: once fully read, the next read starts over from the beginning.
+// As the whole body is kept in memory, it should be used with a reasonable body size read limit.
+func (b *ResponseBuilder) BufferBody() *ResponseBuilder {
+	b.bodyBuffered = true
+	return b
+}
+
+// VerifyContentLength checks, once the response is handled without error, that the response content length,
+// if declared, matches the actual length of the body. The remaining of the body is read to do so.
+// It is useful to catch servers, or mocks, that send a wrong Content-Length header.
+// It can't be used along with DecodeCompressed or DecompressResponseSniff, as decompressed bodies have no content length.
+func (b *ResponseBuilder) VerifyContentLength() *ResponseBuilder {
+	b.verifyContentLength = true
+	return b
+}
+
+// ReadTimeout sets the overall duration allowed to read the response body, starting when the response is handled.
+// Once elapsed, the body is closed and reading it fails, which protects against servers sending the body very slowly.
+func (b *ResponseBuilder) ReadTimeout(d time.Duration) *ResponseBuilder {
+	b.readTimeout = d
+	return b
+}
+
+// DecompressResponseSniff decompresses the response body if it starts with the gzip magic number, whatever the response headers,
+// which handles caches that send gzip bodies without Content-Encoding header. Other bodies are read as is.
+// As the decompressed body length is unknown, the response content length is then unset: a body size read limit
+// applies to the decompressed body, while the zero value limits the compressed body to its content length
+// and the decompressed body to DecompressedBodySizeReadLimit. It can't be used along with VerifyContentLength.
+func (b *ResponseBuilder) DecompressResponseSniff() *ResponseBuilder {
+	b.decompressSniff = true
+	return b
+}
+
+// BeforeHandle sets a function called with the response whatever its status, before the status handler is called.
+// It is useful to record metrics, or capture a header. Functions are called in order, and an error stops the response handling.
+func (b *ResponseBuilder) BeforeHandle(fn ResponseHandler) *ResponseBuilder {
+	b.beforeHandle = append(b.beforeHandle, fn)
+	return b
+}
+
+// DecodeCompressed decompresses the response body according to the response Content-Encoding header, gzip and deflate being supported.
+// It is useful for servers that compress bodies even if not asked to; bodies with other encodings are read as is.
+// A body size read limit applies to the decompressed body, whose length is unknown, while the zero value limits
+// the compressed body to its content length and the decompressed body to DecompressedBodySizeReadLimit, see BodySizeReadLimit.
+// It can't be used along with VerifyContentLength.
+func (b *ResponseBuilder) DecodeCompressed() *ResponseBuilder {
+	b.decodeCompressed = true
 	return b
 }
 
@@ -52,6 +167,24 @@ func (b *ResponseBuilder) OnStatuses(statuses []int, handler ResponseHandler) *R
 	return b
 }
 
+// OnStatusesExcept sets the provided handler to be called if the response http status is any of the provided statuses,
+// except the ones in the except list. Example: OnStatusesExcept(all4xx, []int{http.StatusNotFound}, handler).
+func (b *ResponseBuilder) OnStatusesExcept(statuses, except []int, handler ResponseHandler) *ResponseBuilder {
+	for _, status := range statuses {
+		if !slices.Contains(except, status) {
+			b.OnStatus(status, handler)
+		}
+	}
+	return b
+}
+
+// OnStatusRange sets the provided handler to be called if the response http status is between from and to, included,
+// and no handler is set for the specific response http status. If ranges overlap, the last set one is used.
+func (b *ResponseBuilder) OnStatusRange(from, to int, handler ResponseHandler) *ResponseBuilder {
+	b.statusRanges = append(b.statusRanges, statusRangeHandler{from: from, to: to, handler: handler})
+	return b
+}
+
 // SuccessOnStatus sets the provided statuses handler to return no errors if the response http status is the provided statuses.
 func (b *ResponseBuilder) SuccessOnStatus(statuses ...int) *ResponseBuilder {
 	return b.OnStatuses(statuses, func(*http.Response) error { return nil })
@@ -62,6 +195,70 @@ func (b *ResponseBuilder) ErrorOnStatus(status int, err error) *ResponseBuilder
 	return b.OnStatus(status, func(*http.Response) error { return err })
 }
 
+// ErrorOnClientError sets the provided err to be returned if the response http status is a 4xx client error,
+// unless a handler is set for the specific response http status.
+func (b *ResponseBuilder) ErrorOnClientError(err error) *ResponseBuilder {
+	return b.OnStatusRange(400, 499, func(*http.Response) error { return err })
+}
+
+// ErrorOnServerError sets the provided err to be returned if the response http status is a 5xx server error,
+// unless a handler is set for the specific response http status.
+func (b *ResponseBuilder) ErrorOnServerError(err error) *ResponseBuilder {
+	return b.OnStatusRange(500, 599, func(*http.Response) error { return err })
+}
+
+// OnJSONErrorCode reads the codeField field of the response body JSON object, whatever the response status,
+// and returns the error mapped to the code, without calling the status handler.
+// An absent or empty code, a code absent from the mapping, as well as a body that is not a JSON object,
+// lets the response be handled as usual. The response body is buffered, see BufferBody.
+func (b *ResponseBuilder) OnJSONErrorCode(codeField string, mapping map[string]error) *ResponseBuilder {
+	b.bodyBuffered = true
+	b.jsonErrorCode = func(resp *http.Response, raw []byte) error {
+		var envelope map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &envelope); err != nil {
+			return nil //nolint:nilerr // the body is not a JSON object, and therefore contains no error code
+		}
+
+		rawCode, found := envelope[codeField]
+		if !found {
+			return nil
+		}
+
+		var code string
+		if err := json.Unmarshal(rawCode, &code); err != nil {
+			return fmt.Errorf("%s: unable to parse JSON response body field %q: %w", b.formatResponseError(resp), codeField, err)
+		}
+
+		if code == "" {
+			return nil
+		}
+
+		if err, found := mapping[code]; found {
+			return fmt.Errorf("%s: error code %q: %w", b.formatResponseError(resp), code, err)
+		}
+
+		return nil
+	}
+	return b
+}
+
+// BindHeader sets the value of the response header headerKey in the provided destination if the response http status is the provided status.
+// It is called before, and does not replace, the handler set for the status, like BeforeHandle; a Location header can for instance be
+// bound along with ReceiveJSON on the same status. If no handler is set for the status, the status is considered handled.
+func (b *ResponseBuilder) BindHeader(status int, headerKey string, dest *string) *ResponseBuilder {
+	if b.headerBoundStatuses == nil {
+		b.headerBoundStatuses = make(map[int]struct{})
+	}
+	b.headerBoundStatuses[status] = struct{}{}
+
+	return b.BeforeHandle(func(resp *http.Response) error {
+		if resp.StatusCode == status {
+			*dest = resp.Header.Get(headerKey)
+		}
+		return nil
+	})
+}
+
 // ReceiveJSON parses the response body as JSON (without caring about ContentType header), and sets the result in the provided destination.
 func (b *ResponseBuilder) ReceiveJSON(status int, dest any) *ResponseBuilder {
 	return b.OnStatus(status, func(resp *http.Response) error {
@@ -72,6 +269,280 @@ func (b *ResponseBuilder) ReceiveJSON(status int, dest any) *ResponseBuilder {
 	})
 }
 
+// Receive parses the response body in the provided destination, according to the response Content-Type.
+// JSON (application/json, or any +json type) and XML (application/xml, text/xml, or any +xml type) bodies are decoded
+// into the provided destination, while form (application/x-www-form-urlencoded) bodies are expected to be decoded into an *url.Values.
+// Other content types are considered an error.
+func (b *ResponseBuilder) Receive(status int, dest any) *ResponseBuilder {
+	return b.OnStatus(status, func(resp *http.Response) error {
+		contentType := resp.Header.Get("Content-Type")
+
+		mediaType, _, err := mime.ParseMediaType(contentType)
+		if err != nil {
+			return fmt.Errorf("%s: unable to parse Content-Type %q: %v", b.formatResponseError(resp), contentType, err)
+		}
+
+		switch {
+		case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+			if err := json.NewDecoder(resp.Body).Decode(dest); err != nil {
+				return fmt.Errorf("%s: unable to parse JSON response body: %w", b.formatResponseError(resp), err)
+			}
+		case mediaType == "application/xml" || mediaType == "text/xml" || strings.HasSuffix(mediaType, "+xml"):
+			if err := xml.NewDecoder(resp.Body).Decode(dest); err != nil {
+				return fmt.Errorf("%s: unable to parse XML response body: %w", b.formatResponseError(resp), err)
+			}
+		case mediaType == "application/x-www-form-urlencoded":
+			values, isValues := dest.(*url.Values)
+			if !isValues {
+				return fmt.Errorf("%s: unable to parse form response body in %T, expected *url.Values", b.formatResponseError(resp), dest)
+			}
+
+			raw, err := io.ReadAll(resp.Body)
+			if err != nil {
+				return fmt.Errorf("%s: unable to read body: %w", b.formatResponseError(resp), err)
+			}
+
+			if *values, err = url.ParseQuery(string(raw)); err != nil {
+				return fmt.Errorf("%s: unable to parse form response body: %w", b.formatResponseError(resp), err)
+			}
+		default:
+			return fmt.Errorf("%s: unsupported Content-Type %q", b.formatResponseError(resp), contentType)
+		}
+
+		return nil
+	})
+}
+
+// ReceiveJSONData parses the response body as a JSON object, and sets the value of its dataField field in the provided destination.
+// It is useful to unwrap envelopes like {"data": {...}, "meta": {...}} without declaring a type for each of them.
+// A missing field is considered an error.
+func (b *ResponseBuilder) ReceiveJSONData(status int, dataField string, dest any) *ResponseBuilder {
+	return b.OnStatus(status, func(resp *http.Response) error {
+		var envelope map[string]json.RawMessage
+		if err := json.NewDecoder(resp.Body).Decode(&envelope); err != nil {
+			return fmt.Errorf("%s: unable to parse JSON response body: %w", b.formatResponseError(resp), err)
+		}
+
+		data, found := envelope[dataField]
+		if !found {
+			return fmt.Errorf("%s: field %q not found in JSON response body", b.formatResponseError(resp), dataField)
+		}
+
+		if err := json.Unmarshal(data, &dest); err != nil {
+			return fmt.Errorf("%s: unable to parse JSON response body field %q: %w", b.formatResponseError(resp), dataField, err)
+		}
+
+		return nil
+	})
+}
+
+// ReceiveJSONWithCharset parses the response body as JSON, like ReceiveJSON, but honors the charset parameter of the Content-Type header.
+// If the charset is unset or is UTF-8, the body is parsed as is. Otherwise, the provided charsetReader is used to convert the body to UTF-8.
+// A charsetReader can easily be built using golang.org/x/text/encoding packages, it is not imported by this package to keep the dependency optional.
+func (b *ResponseBuilder) ReceiveJSONWithCharset(status int, dest any, charsetReader CharsetReaderFunc) *ResponseBuilder {
+	return b.OnStatus(status, func(resp *http.Response) error {
+		body, err := b.charsetBody(resp, charsetReader)
+		if err != nil {
+			return err
+		}
+
+		if err := json.NewDecoder(body).Decode(&dest); err != nil {
+			return fmt.Errorf("%s: unable to parse JSON response body: %w", b.formatResponseError(resp), err)
+		}
+		return nil
+	})
+}
+
+// ReceiveString reads the response body as text in the provided destination.
+// The body is converted to UTF-8 using the provided charset reader if the response Content-Type defines a charset other than UTF-8,
+// see charset.Reader for a reader supporting most charsets. The body is considered UTF-8 if no charset is defined.
+func (b *ResponseBuilder) ReceiveString(status int, dest *string, charsetReader CharsetReaderFunc) *ResponseBuilder {
+	return b.OnStatus(status, func(resp *http.Response) error {
+		body, err := b.charsetBody(resp, charsetReader)
+		if err != nil {
+			return err
+		}
+
+		raw, err := io.ReadAll(body)
+		if err != nil {
+			return fmt.Errorf("%s: unable to read body: %w", b.formatResponseError(resp), err)
+		}
+
+		*dest = string(raw)
+		return nil
+	})
+}
+
+// ReceiveJSONPagedMaxPages is the maximum number of pages fetched by ResponseBuilder.ReceiveJSONPaged.
+const ReceiveJSONPagedMaxPages = 1000
+
+// ReceiveJSONPaged parses the response body as JSON in a page created with newPage, and calls onPage with it.
+// As long as onPage returns a non-empty next url, the next page is requested with a GET on it using the provided client
+// (with the same headers as the original request), and is handled the same way. The next url can be relative to the current page url.
+// Each page is expected to be answered with the provided status. To avoid infinite loops, at most ReceiveJSONPagedMaxPages pages are fetched.
+// As headers may contain credentials, next urls on another scheme or host than the current page url are rejected.
+func (b *ResponseBuilder) ReceiveJSONPaged(status int, newPage func() any, onPage func(any) (nextURL string, err error), client Doer) *ResponseBuilder {
+	return b.OnStatus(status, func(resp *http.Response) error {
+		var nextURL *url.URL
+
+		handlePage := func(resp *http.Response) error {
+			page := newPage()
+			if err := json.NewDecoder(resp.Body).Decode(page); err != nil {
+				return fmt.Errorf("%s: unable to parse JSON response body: %w", b.formatResponseError(resp), err)
+			}
+
+			next, err := onPage(page)
+			if err != nil {
+				return fmt.Errorf("%s: unable to handle page: %w", b.formatResponseError(resp), err)
+			}
+
+			nextURL = nil
+			if next != "" {
+				if nextURL, err = resp.Request.URL.Parse(next); err != nil {
+					return fmt.Errorf("%s: unable to parse next page url %q: %v", b.formatResponseError(resp), next, err)
+				}
+				if !strings.EqualFold(nextURL.Scheme, resp.Request.URL.Scheme) || !strings.EqualFold(nextURL.Host, resp.Request.URL.Host) {
+					return fmt.Errorf("%s: next page url %q is not on the same scheme and host", b.formatResponseError(resp), next)
+				}
+			}
+
+			return nil
+		}
+
+		if err := handlePage(resp); err != nil {
+			return err
+		}
+
+		for page := 2; nextURL != nil; page++ {
+			if page > ReceiveJSONPagedMaxPages {
+				return fmt.Errorf("%s: too many pages, stopped after %d pages", b.formatResponseError(resp), ReceiveJSONPagedMaxPages)
+			}
+
+			if err := NewRequest(http.MethodGet, nextURL.String()).
+				Client(client).
+				SetHeaders(resp.Request.Header).
+				Do(resp.Request.Context()).
+				BodySizeReadLimit(b.bodySizeReadLimit).
+				OnStatus(status, handlePage).
+				Error(); err != nil {
+				return fmt.Errorf("unable to handle page %d: %w", page, err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// ReceiveMultipart parses the response body as a multipart body, like multipart/mixed, calling onPart for each part, in order.
+// Parts are streamed, the body size read limit applying to the whole body.
+func (b *ResponseBuilder) ReceiveMultipart(status int, onPart func(*multipart.Part) error) *ResponseBuilder {
+	return b.OnStatus(status, func(resp *http.Response) error {
+		contentType := resp.Header.Get("Content-Type")
+
+		mediaType, params, err := mime.ParseMediaType(contentType)
+		if err != nil {
+			return fmt.Errorf("%s: unable to parse Content-Type %q: %v", b.formatResponseError(resp), contentType, err)
+		}
+
+		if !strings.HasPrefix(mediaType, "multipart/") || params["boundary"] == "" {
+			return fmt.Errorf("%s: Content-Type %q is not a multipart with boundary", b.formatResponseError(resp), contentType)
+		}
+
+		reader := multipart.NewReader(resp.Body, params["boundary"])
+		for i := 0; ; i++ {
+			part, err := reader.NextPart()
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			if err != nil {
+				return fmt.Errorf("%s: unable to read multipart part %d: %w", b.formatResponseError(resp), i, err)
+			}
+
+			if err := onPart(part); err != nil {
+				return fmt.Errorf("%s: unable to handle multipart part %d: %w", b.formatResponseError(resp), i, err)
+			}
+		}
+	})
+}
+
+// Trailers returns the response trailers.
+// Trailers are only populated once the response body has been fully read, therefore it is expected to be called
+// inside a handler after reading the whole body, or after Error returned.
+func (b *ResponseBuilder) Trailers() http.Header {
+	if b.resp == nil {
+		return nil
+	}
+	return b.resp.Trailer
+}
+
+// BytesRead returns the number of bytes consumed from the response body as received, that is before any decompression,
+// whether the body is buffered or read by handlers. The body size read limit stops the body from being consumed further.
+// It is only meaningful once Error has been called.
+func (b *ResponseBuilder) BytesRead() int64 {
+	return b.bytesRead
+}
+
+// ResponseSnapshot stores a copy of a response.
+type ResponseSnapshot struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
+// Snapshot reads the response body, bounded by the body size read limit, and returns a copy of the response.
+// The response body is replaced by the read content, so handlers can still read it when Error is called.
+func (b *ResponseBuilder) Snapshot() (*ResponseSnapshot, error) {
+	if b.builderError != nil {
+		return nil, b.builderError
+	}
+
+	body, err := b.bufferBody()
+	if err != nil {
+		return nil, err
+	}
+
+	return &ResponseSnapshot{
+		StatusCode: b.resp.StatusCode,
+		Header:     b.resp.Header.Clone(),
+		Body:       body,
+	}, nil
+}
+
+// DecodeJSON handles the response, like ResponseBuilder.Error, and returns the response body decoded in JSON if the response status is the provided status.
+// Example: user, err := DecodeJSON[User](api.Do(ctx, api.Get("/users/42")), http.StatusOK).
+func DecodeJSON[T any](rb *ResponseBuilder, status int) (T, error) {
+	var value T
+	if err := rb.ReceiveJSON(status, &value).Error(); err != nil {
+		var zero T
+		return zero, err
+	}
+	return value, nil
+}
+
+// ResponseResult describes how a response was handled.
+type ResponseResult struct {
+	StatusCode int
+	// Handled is true if a handler was set for the response status.
+	Handled bool
+	// Body is the response body, only set if the body is buffered, see BufferBody.
+	Body []byte
+}
+
+// Result is like Error, but also returns a description of how the response was handled, which eases diagnostics and assertions.
+// The result is nil if no response was received.
+func (b *ResponseBuilder) Result() (*ResponseResult, error) {
+	err := b.Error()
+	if b.resp == nil {
+		return nil, err
+	}
+
+	return &ResponseResult{
+		StatusCode: b.resp.StatusCode,
+		Handled:    b.statusHandled,
+		Body:       b.bufferedBody,
+	}, err
+}
+
 // Error applies all the configured attributes on the request's response.
 func (b *ResponseBuilder) Error() error {
 	if b.resp != nil && b.resp.Body != nil {
@@ -83,26 +554,91 @@ func (b *ResponseBuilder) Error() error {
 		return b.builderError
 	}
 
-	if b.bodySizeReadLimit >= 0 {
-		readLimit := b.bodySizeReadLimit
+	if b.readTimeout > 0 {
+		body := &timeoutReadCloser{ReadCloser: b.resp.Body, timeout: b.readTimeout}
+		body.timer = time.AfterFunc(b.readTimeout, body.expire)
+		defer body.timer.Stop()
+		b.resp.Body = body
+	}
 
-		switch {
-		case b.resp.ContentLength < 0:
-		case readLimit == 0:
-			readLimit = b.resp.ContentLength
-		case readLimit > b.resp.ContentLength:
-			readLimit = b.resp.ContentLength
-		case readLimit < b.resp.ContentLength:
-			return fmt.Errorf("%s: content length %d is above read limit %d", b.formatResponseError(b.resp), b.resp.ContentLength, readLimit)
+	if b.verifyContentLength && (b.decodeCompressed || b.decompressSniff) {
+		return fmt.Errorf("%s: content length can't be verified on decompressed bodies", b.formatResponseError(b.resp))
+	}
+
+	wireBody := &countingReadCloser{ReadCloser: b.resp.Body, count: &b.bytesRead}
+	b.resp.Body = wireBody
+
+	var decompressed bool
+	if b.decodeCompressed {
+		var err error
+		if decompressed, err = b.decodeBody(); err != nil {
+			return err
 		}
+	}
 
-		b.resp.Body = io.NopCloser(io.LimitReader(b.resp.Body, readLimit))
+	if b.decompressSniff && !decompressed {
+		if err := b.decompressBody(); err != nil {
+			return err
+		}
+	}
+
+	if b.bodyBuffered {
+		raw, err := b.bufferBody()
+		if err != nil {
+			return err
+		}
+		b.resp.Body = &replayableReadCloser{raw: raw, reader: bytes.NewReader(raw)}
+		b.bufferedBody = raw
+
+		if b.jsonErrorCode != nil {
+			if err := b.jsonErrorCode(b.resp, raw); err != nil {
+				return err
+			}
+		}
+	} else if err := b.limitBody(); err != nil {
+		return err
+	}
+
+	if b.downloadProgress != nil {
+		b.resp.Body = &progressReadCloser{ReadCloser: b.resp.Body, total: b.resp.ContentLength, progress: b.downloadProgress}
 	}
 
+	for _, fn := range b.beforeHandle {
+		if err := fn(b.resp); err != nil {
+			return err
+		}
+	}
+
+	if err := b.handleStatus(); err != nil {
+		return err
+	}
+
+	if b.verifyContentLength {
+		return b.verifyBodyLength(wireBody)
+	}
+
+	return nil
+}
+
+// handleStatus calls the handler set for the response status.
+func (b *ResponseBuilder) handleStatus() error {
 	if statusHandler, exists := b.statusHandler[b.resp.StatusCode]; exists {
+		b.statusHandled = true
 		return statusHandler(b.resp)
 	}
 
+	for i := len(b.statusRanges) - 1; i >= 0; i-- {
+		if statusRange := b.statusRanges[i]; b.resp.StatusCode >= statusRange.from && b.resp.StatusCode <= statusRange.to {
+			b.statusHandled = true
+			return statusRange.handler(b.resp)
+		}
+	}
+
+	if _, isBound := b.headerBoundStatuses[b.resp.StatusCode]; isBound {
+		b.statusHandled = true
+		return nil
+	}
+
 	var errSuffix string
 	if body, _ := io.ReadAll(b.resp.Body); len(body) > 0 {
 		errSuffix += " with b64 body " + base64.StdEncoding.EncodeToString(body)
@@ -110,6 +646,281 @@ func (b *ResponseBuilder) Error() error {
 	return fmt.Errorf("%s: unhandled request status%s", b.formatResponseError(b.resp), errSuffix)
 }
 
+// verifyBodyLength reads the remaining of the response body, and checks that the number of bytes read
+// from the original response body, counted by the provided wireBody, matches the response content length.
+// A body ending before the content length is reached is reported as a mismatch.
+func (b *ResponseBuilder) verifyBodyLength(wireBody *countingReadCloser) error {
+	if b.resp.ContentLength < 0 {
+		return nil
+	}
+
+	if _, err := io.Copy(io.Discard, b.resp.Body); err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return fmt.Errorf("%s: unable to read body: %w", b.formatResponseError(b.resp), err)
+	}
+
+	// body size read limit may stop reading before the end of the original body, which is read to get its exact length
+	if _, err := io.Copy(io.Discard, wireBody); err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return fmt.Errorf("%s: unable to read body: %w", b.formatResponseError(b.resp), err)
+	}
+
+	if read := *wireBody.count; read != b.resp.ContentLength {
+		return fmt.Errorf("%s: content length %d does not match body length %d", b.formatResponseError(b.resp), b.resp.ContentLength, read)
+	}
+
+	return nil
+}
+
+// MustError is like Error but panics if Error returns an error.
+// It is meant to be used in tests and scripts, not in production code.
+func (b *ResponseBuilder) MustError() {
+	if err := b.Error(); err != nil {
+		panic(err)
+	}
+}
+
+// limitBody limits the response body to the configured body size read limit.
+// It is idempotent, the body being limited only once.
+func (b *ResponseBuilder) limitBody() error {
+	if b.bodyLimited || b.bodySizeReadLimit < 0 {
+		return nil
+	}
+
+	readLimit := b.bodySizeReadLimit
+
+	switch {
+	case b.resp.ContentLength < 0:
+	case readLimit == 0:
+		readLimit = b.resp.ContentLength
+	case readLimit > b.resp.ContentLength:
+		readLimit = b.resp.ContentLength
+	case readLimit < b.resp.ContentLength:
+		return fmt.Errorf("%s: content length %d is above read limit %d", b.formatResponseError(b.resp), b.resp.ContentLength, readLimit)
+	}
+
+	if b.bodySizeStrict && b.resp.ContentLength < 0 {
+		b.resp.Body = io.NopCloser(&strictLimitedReader{
+			reader:    b.resp.Body,
+			remaining: readLimit,
+			err:       fmt.Errorf("%s: body is above read limit %d", b.formatResponseError(b.resp), readLimit),
+		})
+	} else {
+		b.resp.Body = io.NopCloser(io.LimitReader(b.resp.Body, readLimit))
+	}
+	b.bodyLimited = true
+
+	return nil
+}
+
+// bufferBody reads the whole response body, bounded by the body size read limit, and closes it.
+// The body is replaced by an in-memory reader on the read content.
+func (b *ResponseBuilder) bufferBody() ([]byte, error) {
+	body := b.resp.Body
+
+	if err := b.limitBody(); err != nil {
+		return nil, err
+	}
+
+	raw, err := io.ReadAll(b.resp.Body)
+	_ = body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("%s: unable to read body: %w", b.formatResponseError(b.resp), err)
+	}
+
+	b.resp.Body = io.NopCloser(bytes.NewReader(raw))
+
+	return raw, nil
+}
+
+// decompressBody peeks the first bytes of the response body, and decompresses the body if they are the gzip magic number.
+// The peeked bytes are restored in any cases.
+func (b *ResponseBuilder) decompressBody() error {
+	b.limitCompressedBody()
+
+	peeked := make([]byte, 2)
+	n, err := io.ReadFull(b.resp.Body, peeked)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return fmt.Errorf("%s: unable to read body: %w", b.formatResponseError(b.resp), err)
+	}
+
+	body := io.MultiReader(bytes.NewReader(peeked[:n]), b.resp.Body)
+	if n < len(peeked) || peeked[0] != 0x1f || peeked[1] != 0x8b {
+		b.resp.Body = &readCloser{Reader: body, Closer: b.resp.Body}
+		return nil
+	}
+
+	gzipReader, err := gzip.NewReader(body)
+	if err != nil {
+		return fmt.Errorf("%s: unable to decompress body: %w", b.formatResponseError(b.resp), err)
+	}
+
+	b.setDecompressedBody(gzipReader)
+
+	return nil
+}
+
+// decodeBody decompresses the response body according to the response Content-Encoding header, and returns whether it did.
+func (b *ResponseBuilder) decodeBody() (bool, error) {
+	var (
+		decompressed io.Reader
+		err          error
+	)
+
+	switch encoding := strings.ToLower(strings.TrimSpace(b.resp.Header.Get("Content-Encoding"))); encoding {
+	case "gzip", "x-gzip":
+		b.limitCompressedBody()
+		decompressed, err = gzip.NewReader(b.resp.Body)
+	case "deflate": // zlib format, as defined by RFC 9110
+		b.limitCompressedBody()
+		decompressed, err = zlib.NewReader(b.resp.Body)
+	default:
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("%s: unable to decompress body: %w", b.formatResponseError(b.resp), err)
+	}
+
+	b.setDecompressedBody(decompressed)
+
+	return true, nil
+}
+
+// limitCompressedBody limits the response body to its content length when no body size read limit is set.
+// The content length no longer applies once the body is decompressed, thus it has to be applied before.
+func (b *ResponseBuilder) limitCompressedBody() {
+	if b.bodySizeReadLimit == 0 && b.resp.ContentLength >= 0 {
+		_ = b.limitBody() // the body is limited to its content length, which can't fail
+	}
+}
+
+// setDecompressedBody replaces the response body with the provided decompressed reader,
+// and updates the response to reflect that the body is decompressed.
+// Without body size read limit, the decompressed body is limited to DecompressedBodySizeReadLimit.
+func (b *ResponseBuilder) setDecompressedBody(decompressed io.Reader) {
+	if b.bodySizeReadLimit == 0 {
+		decompressed = &strictLimitedReader{
+			reader:    decompressed,
+			remaining: DecompressedBodySizeReadLimit,
+			err:       fmt.Errorf("%s: decompressed body is above read limit %d", b.formatResponseError(b.resp), DecompressedBodySizeReadLimit),
+		}
+		b.bodyLimited = true
+	}
+
+	b.resp.Body = &readCloser{Reader: decompressed, Closer: b.resp.Body}
+	b.resp.ContentLength = -1
+	b.resp.Header.Del("Content-Encoding")
+	b.resp.Header.Del("Content-Length")
+	b.resp.Uncompressed = true
+}
+
+func (b *ResponseBuilder) charsetBody(resp *http.Response, charsetReader CharsetReaderFunc) (io.Reader, error) {
+	contentType := resp.Header.Get("Content-Type")
+	if contentType == "" {
+		return resp.Body, nil
+	}
+
+	_, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil, fmt.Errorf("%s: unable to parse Content-Type %q: %v", b.formatResponseError(resp), contentType, err)
+	}
+
+	charset := strings.ToLower(params["charset"])
+	if charset == "" || charset == "utf-8" || charset == "utf8" {
+		return resp.Body, nil
+	}
+
+	if charsetReader == nil {
+		return nil, fmt.Errorf("%s: unsupported charset %q", b.formatResponseError(resp), charset)
+	}
+
+	body, err := charsetReader(charset, resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("%s: unable to read body with charset %q: %w", b.formatResponseError(resp), charset, err)
+	}
+
+	return body, nil
+}
+
 func (*ResponseBuilder) formatResponseError(resp *http.Response) string {
 	return fmt.Sprintf("request %s %s failed with status %d", resp.Request.Method, resp.Request.URL.String(), resp.StatusCode)
 }
+
+// countingReadCloser counts the number of bytes read on the underlying ReadCloser.
+type countingReadCloser struct {
+	io.ReadCloser
+	count *int64
+}
+
+func (r *countingReadCloser) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	*r.count += int64(n)
+	return n, err
+}
+
+// readCloser reads from the reader, and closes the closer.
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// timeoutReadCloser closes the underlying ReadCloser once the timer expires, reads then failing with a timeout error.
+type timeoutReadCloser struct {
+	io.ReadCloser
+	timeout time.Duration
+	timer   *time.Timer
+	expired atomic.Bool
+}
+
+func (r *timeoutReadCloser) expire() {
+	r.expired.Store(true)
+	_ = r.ReadCloser.Close()
+}
+
+func (r *timeoutReadCloser) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	if err != nil && r.expired.Load() {
+		return n, fmt.Errorf("body read timeout of %s exceeded", r.timeout)
+	}
+	return n, err
+}
+
+// replayableReadCloser reads from an in-memory content, and starts over once the content is fully read or closed.
+type replayableReadCloser struct {
+	raw    []byte
+	reader *bytes.Reader
+}
+
+func (r *replayableReadCloser) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	if errors.Is(err, io.EOF) {
+		r.reader.Reset(r.raw)
+	}
+	return n, err
+}
+
+func (r *replayableReadCloser) Close() error {
+	r.reader.Reset(r.raw)
+	return nil
+}
+
+// strictLimitedReader reads from the underlying reader, but fails if more than the remaining bytes are available.
+type strictLimitedReader struct {
+	reader    io.Reader
+	remaining int64
+	err       error
+}
+
+func (r *strictLimitedReader) Read(p []byte) (int, error) {
+	if int64(len(p)) > r.remaining+1 {
+		p = p[:r.remaining+1]
+	}
+
+	n, err := r.reader.Read(p)
+	if int64(n) > r.remaining {
+		n = int(r.remaining)
+		r.remaining = 0
+		return n, r.err
+	}
+
+	r.remaining -= int64(n)
+	return n, err
+}
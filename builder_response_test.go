@@ -1,16 +1,25 @@
 package httpclient
 
 import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
+	"net/textproto"
 	"net/url"
+	"strconv"
 	"strings"
 	"testing"
+	"time"
 
+	"golang.org/x/text/encoding/htmlindex"
 	"gotest.tools/v3/assert"
 	"gotest.tools/v3/assert/cmp"
 )
@@ -22,6 +31,412 @@ func Test_ResponseBuilder_BodySizeReadLimit(t *testing.T) {
 	assert.Check(t, resp.bodySizeReadLimit == 42)
 }
 
+func Test_ResponseBuilder_BodySizeReadLimitStrict(t *testing.T) {
+	httpServer, httpServerURL := newHTTPServerForTesting(t, func(rw http.ResponseWriter, r *http.Request) {
+		rw.WriteHeader(http.StatusOK)
+		rw.(http.Flusher).Flush() // forces a chunked response, without content-length
+		_, err := rw.Write([]byte(`"hello world!"`))
+		assert.NilError(t, err)
+	})
+
+	readBody := func(limit int64) (string, error) {
+		var body string
+		err := NewRequest(http.MethodGet, httpServerURL.String()).
+			Client(httpServer.Client()).
+			Do(context.Background()).
+			BodySizeReadLimitStrict(limit).
+			OnStatus(http.StatusOK, func(resp *http.Response) error {
+				assert.Check(t, resp.ContentLength == -1)
+				raw, err := io.ReadAll(resp.Body)
+				body = string(raw)
+				return err
+			}).
+			Error()
+		return body, err
+	}
+
+	t.Run("body below limit", func(t *testing.T) {
+		body, err := readBody(14)
+		assert.NilError(t, err)
+		assert.Check(t, cmp.Equal(body, `"hello world!"`))
+	})
+
+	t.Run("body above limit", func(t *testing.T) {
+		body, err := readBody(13)
+		assert.ErrorContains(t, err, "body is above read limit 13")
+		assert.Check(t, cmp.Equal(body, `"hello world!`))
+	})
+
+	t.Run("not strict anymore", func(t *testing.T) {
+		resp := newResponse().BodySizeReadLimitStrict(13)
+		assert.Check(t, resp.bodySizeStrict)
+		resp = resp.BodySizeReadLimit(13)
+		assert.Check(t, !resp.bodySizeStrict)
+	})
+}
+
+func Test_ResponseBuilder_OnDownloadProgress(t *testing.T) {
+	body := strings.Repeat("a", 1<<20)
+
+	httpServer, httpServerURL := newHTTPServerForTesting(t, func(rw http.ResponseWriter, r *http.Request) {
+		rw.Header().Set("Content-Length", strconv.Itoa(len(body)))
+		rw.WriteHeader(http.StatusOK)
+		_, err := rw.Write([]byte(body))
+		assert.NilError(t, err)
+	})
+
+	var progress [][2]int64
+
+	assert.NilError(t, NewRequest(http.MethodGet, httpServerURL.String()).
+		Client(httpServer.Client()).
+		Do(context.Background()).
+		BodySizeReadLimit(-1).
+		OnDownloadProgress(func(bytesRead, total int64) {
+			progress = append(progress, [2]int64{bytesRead, total})
+		}).
+		OnStatus(http.StatusOK, func(resp *http.Response) error {
+			_, err := io.Copy(io.Discard, resp.Body)
+			return err
+		}).
+		Error(),
+	)
+
+	assert.Assert(t, len(progress) > 1)
+	for i := 1; i < len(progress); i++ {
+		assert.Check(t, progress[i][0] > progress[i-1][0])
+	}
+	assert.Check(t, cmp.DeepEqual(progress[len(progress)-1], [2]int64{int64(len(body)), int64(len(body))}))
+}
+
+func Test_ResponseBuilder_BufferBody(t *testing.T) {
+	httpServer, httpServerURL := newHTTPServerForTesting(t, func(rw http.ResponseWriter, r *http.Request) {
+		rw.WriteHeader(http.StatusOK)
+		_, err := rw.Write([]byte(`{"hello":"world"}`))
+		assert.NilError(t, err)
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		resp := NewRequest(http.MethodGet, httpServerURL.String()).Client(httpServer.Client()).Do(context.Background())
+		assert.Check(t, !resp.bodyBuffered)
+		resp = resp.BufferBody()
+		assert.Check(t, resp.bodyBuffered)
+
+		assert.NilError(t, resp.OnStatus(http.StatusOK, func(resp *http.Response) error {
+			logged, err := io.ReadAll(resp.Body)
+			assert.NilError(t, err)
+			assert.Check(t, cmp.Equal(string(logged), `{"hello":"world"}`))
+
+			var body map[string]string
+			assert.NilError(t, json.NewDecoder(resp.Body).Decode(&body))
+			assert.Check(t, cmp.DeepEqual(body, map[string]string{"hello": "world"}))
+			return nil
+		}).Error())
+	})
+
+	t.Run("ko", func(t *testing.T) {
+		assert.ErrorContains(t, NewRequest(http.MethodGet, httpServerURL.String()).
+			Client(httpServer.Client()).
+			Do(context.Background()).
+			BodySizeReadLimit(2).
+			BufferBody().
+			Error(),
+			"content length 17 is above read limit 2",
+		)
+	})
+}
+
+func Test_ResponseBuilder_VerifyContentLength(t *testing.T) {
+	newResponseBuilder := func(contentLength int64, body string) *ResponseBuilder {
+		responseBuilder := newResponseBuilderForTesting(http.StatusOK, nil, []byte(body))
+		responseBuilder.resp.ContentLength = contentLength
+		return responseBuilder
+	}
+
+	t.Run("ok", func(t *testing.T) {
+		for _, contentLength := range []int64{5, -1} {
+			assert.NilError(t, newResponseBuilder(contentLength, "hello").BodySizeReadLimit(-1).VerifyContentLength().SuccessOnStatus(http.StatusOK).Error())
+		}
+
+		assert.NilError(t, newResponseBuilder(5, "hello").VerifyContentLength().BufferBody().OnStatus(http.StatusOK, func(resp *http.Response) error {
+			_, err := io.ReadAll(resp.Body)
+			return err
+		}).Error())
+	})
+
+	t.Run("ko", func(t *testing.T) {
+		t.Run("body shorter than content length", func(t *testing.T) {
+			assert.ErrorContains(t, newResponseBuilder(20, "hello").VerifyContentLength().SuccessOnStatus(http.StatusOK).Error(),
+				"content length 20 does not match body length 5",
+			)
+		})
+
+		t.Run("body longer than content length", func(t *testing.T) {
+			assert.ErrorContains(t, newResponseBuilder(3, "hello").VerifyContentLength().BufferBody().SuccessOnStatus(http.StatusOK).Error(),
+				"content length 3 does not match body length 5",
+			)
+		})
+
+		t.Run("server with wrong content length", func(t *testing.T) {
+			httpServer, httpServerURL := newHTTPServerForTesting(t, func(rw http.ResponseWriter, r *http.Request) {
+				conn, buf, err := rw.(http.Hijacker).Hijack()
+				assert.NilError(t, err)
+				defer func() { _ = conn.Close() }()
+
+				_, err = buf.WriteString("HTTP/1.1 200 OK\r\nContent-Length: 20\r\n\r\nhello")
+				assert.NilError(t, err)
+				assert.NilError(t, buf.Flush())
+			})
+
+			assert.ErrorContains(t, NewRequest(http.MethodGet, httpServerURL.String()).
+				Client(httpServer.Client()).
+				Do(context.Background()).
+				VerifyContentLength().
+				SuccessOnStatus(http.StatusOK).
+				Error(),
+				"content length 20 does not match body length 5",
+			)
+		})
+	})
+}
+
+func Test_ResponseBuilder_ReadTimeout(t *testing.T) {
+	httpServer, httpServerURL := newHTTPServerForTesting(t, func(rw http.ResponseWriter, r *http.Request) {
+		rw.WriteHeader(http.StatusOK)
+		if r.URL.Query().Get("slow") == "" {
+			_, _ = rw.Write([]byte("hello"))
+			return
+		}
+
+		for i := 0; i < 100; i++ {
+			_, _ = rw.Write([]byte("a"))
+			rw.(http.Flusher).Flush()
+
+			select {
+			case <-time.After(50 * time.Millisecond):
+			case <-r.Context().Done():
+				return
+			}
+		}
+	})
+
+	receive := func(endpoint string) (string, error) {
+		var body string
+		err := NewRequest(http.MethodGet, endpoint).
+			Client(httpServer.Client()).
+			Do(context.Background()).
+			BodySizeReadLimit(KB).
+			ReadTimeout(200*time.Millisecond).
+			ReceiveString(http.StatusOK, &body, nil).
+			Error()
+		return body, err
+	}
+
+	t.Run("ok", func(t *testing.T) {
+		body, err := receive(httpServerURL.String())
+		assert.NilError(t, err)
+		assert.Check(t, cmp.Equal(body, "hello"))
+	})
+
+	t.Run("ko", func(t *testing.T) {
+		start := time.Now()
+		_, err := receive(httpServerURL.String() + "?slow=1")
+		assert.ErrorContains(t, err, "unable to read body: body read timeout of 200ms exceeded")
+		assert.Check(t, time.Since(start) < 2*time.Second)
+	})
+}
+
+func Test_ResponseBuilder_DecompressResponseSniff(t *testing.T) {
+	var gzipped bytes.Buffer
+	gzipWriter := gzip.NewWriter(&gzipped)
+	_, err := gzipWriter.Write([]byte("hello world!"))
+	assert.NilError(t, err)
+	assert.NilError(t, gzipWriter.Close())
+
+	t.Run("ok", func(t *testing.T) {
+		for name, test := range map[string]struct {
+			body     []byte
+			expected string
+		}{
+			"gzipped body without header": {body: gzipped.Bytes(), expected: "hello world!"},
+			"plain body":                  {body: []byte("hello world!"), expected: "hello world!"},
+			"one byte body":               {body: []byte{0x1f}, expected: "\x1f"},
+			"empty body":                  {body: nil, expected: ""},
+		} {
+			test := test
+			t.Run(name, func(t *testing.T) {
+				var body string
+				assert.NilError(t, newResponseBuilderForTesting(http.StatusOK, nil, test.body).
+					BodySizeReadLimit(KB).
+					DecompressResponseSniff().
+					ReceiveString(http.StatusOK, &body, nil).
+					Error(),
+				)
+				assert.Check(t, cmp.Equal(body, test.expected))
+			})
+		}
+	})
+
+	t.Run("ok without body size read limit", func(t *testing.T) {
+		var body string
+		assert.NilError(t, newResponseBuilderForTesting(http.StatusOK, nil, gzipped.Bytes()).
+			DecompressResponseSniff().
+			ReceiveString(http.StatusOK, &body, nil).
+			Error(),
+		)
+		assert.Check(t, cmp.Equal(body, "hello world!"))
+	})
+
+	t.Run("ko", func(t *testing.T) {
+		err := newResponseBuilderForTesting(http.StatusOK, nil, []byte{0x1f, 0x8b, 0x00}).DecompressResponseSniff().SuccessOnStatus(http.StatusOK).Error()
+		assert.ErrorContains(t, err, "unable to decompress body")
+	})
+}
+
+func Test_ResponseBuilder_BeforeHandle(t *testing.T) {
+	t.Run("ok", func(t *testing.T) {
+		for _, status := range []int{http.StatusOK, http.StatusTeapot} {
+			var calls []string
+
+			err := newResponseBuilderForTesting(status, nil, nil).
+				BeforeHandle(func(resp *http.Response) error {
+					calls = append(calls, "first "+strconv.Itoa(resp.StatusCode))
+					return nil
+				}).
+				BeforeHandle(func(resp *http.Response) error {
+					calls = append(calls, "second "+strconv.Itoa(resp.StatusCode))
+					return nil
+				}).
+				OnStatus(http.StatusOK, func(*http.Response) error {
+					calls = append(calls, "handler")
+					return nil
+				}).
+				Error()
+
+			if status == http.StatusOK {
+				assert.NilError(t, err)
+				assert.Check(t, cmp.DeepEqual(calls, []string{"first 200", "second 200", "handler"}))
+			} else {
+				assert.ErrorContains(t, err, "unhandled request status")
+				assert.Check(t, cmp.DeepEqual(calls, []string{"first 418", "second 418"}))
+			}
+		}
+	})
+
+	t.Run("ko", func(t *testing.T) {
+		var called bool
+
+		err := newResponseBuilderForTesting(http.StatusOK, nil, nil).
+			BeforeHandle(func(*http.Response) error { return errors.New("boom") }).
+			BeforeHandle(func(*http.Response) error {
+				called = true
+				return nil
+			}).
+			SuccessOnStatus(http.StatusOK).
+			Error()
+		assert.Error(t, err, "boom")
+		assert.Check(t, !called)
+	})
+}
+
+func Test_ResponseBuilder_DecodeCompressed(t *testing.T) {
+	compress := func(t *testing.T, newWriter func(io.Writer) io.WriteCloser) []byte {
+		var compressed bytes.Buffer
+		writer := newWriter(&compressed)
+		_, err := writer.Write([]byte("hello world!"))
+		assert.NilError(t, err)
+		assert.NilError(t, writer.Close())
+		return compressed.Bytes()
+	}
+
+	gzipped := compress(t, func(w io.Writer) io.WriteCloser { return gzip.NewWriter(w) })
+	deflated := compress(t, func(w io.Writer) io.WriteCloser { return zlib.NewWriter(w) })
+
+	newResponseBuilder := func(encoding string, body []byte) *ResponseBuilder {
+		return newResponseBuilderForTesting(http.StatusOK, http.Header{"Content-Encoding": {encoding}}, body)
+	}
+
+	t.Run("ok", func(t *testing.T) {
+		for name, test := range map[string]struct {
+			encoding string
+			body     []byte
+			expected string
+		}{
+			"gzip":             {encoding: "gzip", body: gzipped, expected: "hello world!"},
+			"deflate":          {encoding: "Deflate", body: deflated, expected: "hello world!"},
+			"identity":         {encoding: "identity", body: []byte("hello world!"), expected: "hello world!"},
+			"unknown encoding": {encoding: "br", body: []byte("brotli"), expected: "brotli"},
+		} {
+			test := test
+			t.Run(name, func(t *testing.T) {
+				var body string
+				responseBuilder := newResponseBuilder(test.encoding, test.body)
+				assert.NilError(t, responseBuilder.
+					BodySizeReadLimit(KB).
+					DecodeCompressed().
+					DecompressResponseSniff().
+					ReceiveString(http.StatusOK, &body, nil).
+					Error(),
+				)
+				assert.Check(t, cmp.Equal(body, test.expected))
+			})
+		}
+	})
+
+	t.Run("ok without body size read limit", func(t *testing.T) {
+		var body string
+		assert.NilError(t, newResponseBuilder("gzip", gzipped).
+			DecodeCompressed().
+			ReceiveString(http.StatusOK, &body, nil).
+			Error(),
+		)
+		assert.Check(t, cmp.Equal(body, "hello world!"))
+	})
+
+	t.Run("bytes read are compressed bytes", func(t *testing.T) {
+		responseBuilder := newResponseBuilder("gzip", gzipped).DecodeCompressed().ReceiveString(http.StatusOK, new(string), nil)
+		assert.NilError(t, responseBuilder.Error())
+		assert.Equal(t, responseBuilder.BytesRead(), int64(len(gzipped)))
+	})
+
+	t.Run("ko", func(t *testing.T) {
+		t.Run("decompressed body above default limit", func(t *testing.T) {
+			var bomb bytes.Buffer
+			writer := gzip.NewWriter(&bomb)
+			_, err := writer.Write(make([]byte, DecompressedBodySizeReadLimit+1))
+			assert.NilError(t, err)
+			assert.NilError(t, writer.Close())
+
+			err = newResponseBuilder("gzip", bomb.Bytes()).
+				DecodeCompressed().
+				OnStatus(http.StatusOK, func(resp *http.Response) error {
+					_, err := io.Copy(io.Discard, resp.Body)
+					return err
+				}).
+				Error()
+			assert.ErrorContains(t, err, "decompressed body is above read limit 33554432")
+		})
+
+		t.Run("with content length verification", func(t *testing.T) {
+			err := newResponseBuilder("gzip", gzipped).DecodeCompressed().VerifyContentLength().SuccessOnStatus(http.StatusOK).Error()
+			assert.ErrorContains(t, err, "content length can't be verified on decompressed bodies")
+		})
+
+		t.Run("invalid compressed body", func(t *testing.T) {
+			err := newResponseBuilder("gzip", []byte("hello world!")).DecodeCompressed().SuccessOnStatus(http.StatusOK).Error()
+			assert.ErrorContains(t, err, "unable to decompress body")
+		})
+
+		t.Run("limit applies to decompressed body", func(t *testing.T) {
+			err := newResponseBuilder("gzip", gzipped).
+				BodySizeReadLimitStrict(5).
+				DecodeCompressed().
+				ReceiveString(http.StatusOK, new(string), nil).
+				Error()
+			assert.ErrorContains(t, err, "body is above read limit 5")
+		})
+	})
+}
+
 func Test_ResponseBuilder_OnStatus(t *testing.T) {
 	called := make(map[int]int)
 	resp := newResponse()
@@ -73,6 +488,20 @@ func Test_ResponseBuilder_OnStatuses(t *testing.T) {
 	assert.Check(t, cmp.Equal(called, 2))
 }
 
+func Test_ResponseBuilder_OnStatusesExcept(t *testing.T) {
+	var statuses []int
+	for status := 400; status < 500; status++ {
+		statuses = append(statuses, status)
+	}
+
+	resp := newResponse().OnStatusesExcept(statuses, []int{http.StatusNotFound}, func(*http.Response) error { return nil })
+
+	for _, status := range statuses {
+		_, exists := resp.statusHandler[status]
+		assert.Check(t, exists == (status != http.StatusNotFound), "status %d", status)
+	}
+}
+
 func Test_ResponseBuilder_ErrorOnStatus(t *testing.T) {
 	anError := errors.New("an error")
 	resp := newResponse()
@@ -87,6 +516,61 @@ func Test_ResponseBuilder_ErrorOnStatus(t *testing.T) {
 	assert.Check(t, cmp.ErrorIs(resp.statusHandler[http.StatusBadRequest](nil), anError))
 }
 
+func Test_ResponseBuilder_OnStatusRange(t *testing.T) {
+	httpServer, httpServerURL := newHTTPServerForTesting(t, func(rw http.ResponseWriter, r *http.Request) {
+		status, err := strconv.Atoi(r.URL.Query().Get("status"))
+		assert.NilError(t, err)
+		rw.WriteHeader(status)
+	})
+
+	doRequest := func(status int) error {
+		return NewRequest(http.MethodGet, httpServerURL.String()).
+			Client(httpServer.Client()).
+			SetQueryParam("status", strconv.Itoa(status)).
+			Do(context.Background()).
+			OnStatusRange(200, 299, func(*http.Response) error { return errors.New("2xx") }).
+			OnStatusRange(200, 209, func(*http.Response) error { return errors.New("20x") }).
+			OnStatus(http.StatusNoContent, func(*http.Response) error { return errors.New("204") }).
+			Error()
+	}
+
+	assert.Error(t, doRequest(http.StatusOK), "20x")
+	assert.Error(t, doRequest(http.StatusNoContent), "204")
+	assert.Error(t, doRequest(http.StatusIMUsed), "2xx")
+	assert.ErrorContains(t, doRequest(http.StatusMultipleChoices), "unhandled request status")
+}
+
+func Test_ResponseBuilder_ErrorOnClientError_ErrorOnServerError(t *testing.T) {
+	var (
+		errClient   = errors.New("client error")
+		errServer   = errors.New("server error")
+		errNotFound = errors.New("not found")
+	)
+
+	httpServer, httpServerURL := newHTTPServerForTesting(t, func(rw http.ResponseWriter, r *http.Request) {
+		status, err := strconv.Atoi(r.URL.Query().Get("status"))
+		assert.NilError(t, err)
+		rw.WriteHeader(status)
+	})
+
+	doRequest := func(status int) error {
+		return NewRequest(http.MethodGet, httpServerURL.String()).
+			Client(httpServer.Client()).
+			SetQueryParam("status", strconv.Itoa(status)).
+			Do(context.Background()).
+			ErrorOnStatus(http.StatusNotFound, errNotFound).
+			ErrorOnClientError(errClient).
+			ErrorOnServerError(errServer).
+			SuccessOnStatus(http.StatusOK).
+			Error()
+	}
+
+	assert.NilError(t, doRequest(http.StatusOK))
+	assert.ErrorIs(t, doRequest(http.StatusBadRequest), errClient)
+	assert.ErrorIs(t, doRequest(http.StatusNotFound), errNotFound)
+	assert.ErrorIs(t, doRequest(http.StatusBadGateway), errServer)
+}
+
 func Test_ResponseBuilder_SuccessOnStatus(t *testing.T) {
 	resp := newResponse()
 
@@ -104,6 +588,97 @@ func Test_ResponseBuilder_SuccessOnStatus(t *testing.T) {
 	assert.Check(t, resp.statusHandler[http.StatusTeapot](nil) == nil)
 }
 
+func Test_ResponseBuilder_OnJSONErrorCode(t *testing.T) {
+	errUserExists := errors.New("user exists")
+
+	echoResponse := newEchoResponseForTesting(t)
+	doRequest := func(body string, dest any) error {
+		return echoResponse("application/json", body).
+			OnJSONErrorCode("error_code", map[string]error{"USER_EXISTS": errUserExists}).
+			ReceiveJSON(http.StatusOK, dest).
+			Error()
+	}
+
+	t.Run("mapped code", func(t *testing.T) {
+		var dest map[string]any
+		err := doRequest(`{"error_code":"USER_EXISTS"}`, &dest)
+		assert.ErrorIs(t, err, errUserExists)
+		assert.ErrorContains(t, err, `error code "USER_EXISTS"`)
+		assert.Check(t, dest == nil)
+	})
+
+	t.Run("unmapped code", func(t *testing.T) {
+		var dest map[string]any
+		assert.NilError(t, doRequest(`{"error_code":"UNKNOWN","id":42}`, &dest))
+		assert.Check(t, dest["id"] == float64(42))
+	})
+
+	t.Run("no code", func(t *testing.T) {
+		for _, body := range []string{`{"error_code":"","id":42}`, `{"id":42}`} {
+			var dest map[string]any
+			assert.NilError(t, doRequest(body, &dest))
+			assert.Check(t, dest["id"] == float64(42))
+		}
+
+		var dest []int
+		assert.NilError(t, doRequest(`[42]`, &dest))
+		assert.DeepEqual(t, dest, []int{42})
+	})
+
+	t.Run("ko", func(t *testing.T) {
+		var dest map[string]any
+		assert.ErrorContains(t, doRequest(`{"error_code":42}`, &dest), `unable to parse JSON response body field "error_code"`)
+	})
+}
+
+func Test_ResponseBuilder_BindHeader(t *testing.T) {
+	httpServer, httpServerURL := newHTTPServerForTesting(t, func(rw http.ResponseWriter, r *http.Request) {
+		rw.Header().Set("Location", "/users/42")
+		rw.WriteHeader(http.StatusCreated)
+		_, _ = rw.Write([]byte(`{"id":42}`))
+	})
+
+	doRequest := func() *ResponseBuilder {
+		return NewRequest(http.MethodPost, httpServerURL.String()).
+			Client(httpServer.Client()).
+			Do(context.Background())
+	}
+
+	t.Run("alone", func(t *testing.T) {
+		var location string
+
+		assert.NilError(t, doRequest().
+			BindHeader(http.StatusCreated, "location", &location).
+			BindHeader(http.StatusOK, "location", new(string)).
+			Error(),
+		)
+		assert.Equal(t, location, "/users/42")
+	})
+
+	t.Run("with a status handler", func(t *testing.T) {
+		var (
+			location, locationBefore string
+			body, bodyBefore         map[string]int
+		)
+
+		assert.NilError(t, doRequest().
+			BindHeader(http.StatusCreated, "Location", &location).
+			ReceiveJSON(http.StatusCreated, &body).
+			Error(),
+		)
+		assert.Equal(t, location, "/users/42")
+		assert.Check(t, cmp.DeepEqual(body, map[string]int{"id": 42}))
+
+		assert.NilError(t, doRequest().
+			ReceiveJSON(http.StatusCreated, &bodyBefore).
+			BindHeader(http.StatusCreated, "Location", &locationBefore).
+			Error(),
+		)
+		assert.Equal(t, locationBefore, "/users/42")
+		assert.Check(t, cmp.DeepEqual(bodyBefore, map[string]int{"id": 42}))
+	})
+}
+
 func Test_ResponseBuilder_ReceiveJSON(t *testing.T) {
 	type responseBody struct {
 		Hello string `json:"hello"`
@@ -142,6 +717,551 @@ func Test_ResponseBuilder_ReceiveJSON(t *testing.T) {
 	})
 }
 
+func Test_ResponseBuilder_Receive(t *testing.T) {
+	type user struct {
+		ID   int    `json:"id" xml:"id"`
+		Name string `json:"name" xml:"name"`
+	}
+
+	doRequest := newEchoResponseForTesting(t)
+
+	t.Run("ok", func(t *testing.T) {
+		for contentType, body := range map[string]string{
+			"application/json; charset=utf-8": `{"id":42,"name":"foo"}`,
+			"application/problem+json":        `{"id":42,"name":"foo"}`,
+			"application/xml":                 `<user><id>42</id><name>foo</name></user>`,
+			"text/xml; charset=utf-8":         `<user><id>42</id><name>foo</name></user>`,
+		} {
+			var dest user
+			assert.NilError(t, doRequest(contentType, body).Receive(http.StatusOK, &dest).Error(), contentType)
+			assert.Equal(t, dest, user{ID: 42, Name: "foo"}, contentType)
+		}
+
+		var values url.Values
+		assert.NilError(t, doRequest("application/x-www-form-urlencoded", "id=42&name=foo").Receive(http.StatusOK, &values).Error())
+		assert.DeepEqual(t, values, url.Values{"id": {"42"}, "name": {"foo"}})
+	})
+
+	t.Run("ko", func(t *testing.T) {
+		var dest user
+		assert.ErrorContains(t, doRequest("text/plain", "foo").Receive(http.StatusOK, &dest).Error(), `unsupported Content-Type "text/plain"`)
+		assert.ErrorContains(t, doRequest("", "foo").Receive(http.StatusOK, &dest).Error(), `unable to parse Content-Type ""`)
+		assert.ErrorContains(t, doRequest("application/json", "<user/>").Receive(http.StatusOK, &dest).Error(), "unable to parse JSON response body")
+		assert.ErrorContains(t, doRequest("application/xml", "{}").Receive(http.StatusOK, &dest).Error(), "unable to parse XML response body")
+		assert.ErrorContains(t, doRequest("application/x-www-form-urlencoded", "id=42").Receive(http.StatusOK, &dest).Error(),
+			"unable to parse form response body in *httpclient.user, expected *url.Values",
+		)
+	})
+}
+
+func Test_ResponseBuilder_ReceiveJSONData(t *testing.T) {
+	type user struct {
+		ID   int    `json:"id"`
+		Name string `json:"name"`
+	}
+
+	echoResponse := newEchoResponseForTesting(t)
+	doRequest := func(body string) *ResponseBuilder { return echoResponse("application/json", body) }
+
+	t.Run("ok", func(t *testing.T) {
+		var dest user
+		assert.NilError(t, doRequest(`{"data":{"id":42,"name":"foo"},"meta":{"page":1}}`).
+			ReceiveJSONData(http.StatusOK, "data", &dest).
+			Error(),
+		)
+		assert.Equal(t, dest, user{ID: 42, Name: "foo"})
+	})
+
+	t.Run("ko", func(t *testing.T) {
+		t.Run("body is not an object", func(t *testing.T) {
+			var dest user
+			assert.ErrorContains(t, doRequest(`[]`).ReceiveJSONData(http.StatusOK, "data", &dest).Error(),
+				"unable to parse JSON response body",
+			)
+		})
+
+		t.Run("missing field", func(t *testing.T) {
+			var dest user
+			assert.ErrorContains(t, doRequest(`{"meta":{}}`).ReceiveJSONData(http.StatusOK, "data", &dest).Error(),
+				`field "data" not found in JSON response body`,
+			)
+		})
+
+		t.Run("invalid field", func(t *testing.T) {
+			var dest user
+			assert.ErrorContains(t, doRequest(`{"data":{"id":"42"}}`).ReceiveJSONData(http.StatusOK, "data", &dest).Error(),
+				`unable to parse JSON response body field "data"`,
+			)
+		})
+	})
+}
+
+func Test_ResponseBuilder_ReceiveJSONWithCharset(t *testing.T) {
+	type responseBody struct {
+		Hello string `json:"hello"`
+	}
+
+	echoResponse := newEchoResponseForTesting(t)
+
+	// conversions with charset.Reader are tested in the charset package, which can't be imported here
+	charsetReader := func(charset string, input io.Reader) (io.Reader, error) {
+		if charset != "iso-8859-1" {
+			return nil, fmt.Errorf("unknown charset %q", charset)
+		}
+		return input, nil
+	}
+
+	doRequest := func(contentType string) *ResponseBuilder {
+		return echoResponse(contentType, `{"hello":"world"}`)
+	}
+
+	t.Run("ok", func(t *testing.T) {
+		for _, contentType := range []string{"application/json", "application/json; charset=UTF-8", "application/json; charset=ISO-8859-1"} {
+			var body responseBody
+			assert.NilError(t, doRequest(contentType).
+				ReceiveJSONWithCharset(http.StatusOK, &body, charsetReader).
+				Error(),
+			)
+			assert.Equal(t, body, responseBody{Hello: "world"})
+		}
+	})
+
+	t.Run("ko", func(t *testing.T) {
+		t.Run("invalid content type", func(t *testing.T) {
+			var body responseBody
+			assert.ErrorContains(t, doRequest("application/json; charset").
+				ReceiveJSONWithCharset(http.StatusOK, &body, charsetReader).
+				Error(),
+				"unable to parse Content-Type",
+			)
+		})
+
+		t.Run("no charset reader", func(t *testing.T) {
+			var body responseBody
+			assert.ErrorContains(t, doRequest("application/json; charset=ISO-8859-1").
+				ReceiveJSONWithCharset(http.StatusOK, &body, nil).
+				Error(),
+				`unsupported charset "iso-8859-1"`,
+			)
+		})
+
+		t.Run("unknown charset", func(t *testing.T) {
+			var body responseBody
+			assert.ErrorContains(t, doRequest("application/json; charset=foo").
+				ReceiveJSONWithCharset(http.StatusOK, &body, charsetReader).
+				Error(),
+				`unable to read body with charset "foo"`,
+			)
+		})
+	})
+}
+
+func Test_ResponseBuilder_ReceiveString(t *testing.T) {
+	httpServer, httpServerURL := newHTTPServerForTesting(t, func(rw http.ResponseWriter, r *http.Request) {
+		rw.Header().Set("Content-Type", "text/plain; charset=ISO-8859-1")
+		rw.WriteHeader(http.StatusOK)
+		_, err := rw.Write([]byte("caf\xe9")) // café in ISO-8859-1
+		assert.NilError(t, err)
+	})
+
+	var body string
+	assert.NilError(t, NewRequest(http.MethodGet, httpServerURL.String()).
+		Client(httpServer.Client()).
+		Do(context.Background()).
+		ReceiveString(http.StatusOK, &body, func(charset string, input io.Reader) (io.Reader, error) {
+			enc, err := htmlindex.Get(charset)
+			if err != nil {
+				return nil, err
+			}
+			return enc.NewDecoder().Reader(input), nil
+		}).
+		Error(),
+	)
+	assert.Equal(t, body, "café")
+}
+
+func Test_ResponseBuilder_ReceiveJSONPaged(t *testing.T) {
+	type page struct {
+		Items []int  `json:"items"`
+		Next  string `json:"next"`
+	}
+
+	httpServer, httpServerURL := newHTTPServerForTesting(t, func(rw http.ResponseWriter, r *http.Request) {
+		assert.Check(t, r.Header.Get("Authorization") == "secret")
+
+		switch r.URL.Path {
+		case "/items":
+			rw.WriteHeader(http.StatusOK)
+			assert.NilError(t, json.NewEncoder(rw).Encode(page{Items: []int{1, 2}, Next: "/items/2"}))
+		case "/items/2":
+			rw.WriteHeader(http.StatusOK)
+			assert.NilError(t, json.NewEncoder(rw).Encode(page{Items: []int{3}}))
+		case "/loop":
+			rw.WriteHeader(http.StatusOK)
+			assert.NilError(t, json.NewEncoder(rw).Encode(page{Next: "/loop"}))
+		default:
+			rw.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	doRequest := func(path string) *ResponseBuilder {
+		return NewRequest(http.MethodGet, httpServerURL.String()+path).
+			Client(httpServer.Client()).
+			SetHeader("Authorization", "secret").
+			Do(context.Background())
+	}
+
+	t.Run("ok", func(t *testing.T) {
+		var items []int
+
+		assert.NilError(t, doRequest("/items").
+			ReceiveJSONPaged(http.StatusOK,
+				func() any { return new(page) },
+				func(p any) (string, error) {
+					items = append(items, p.(*page).Items...)
+					return p.(*page).Next, nil
+				},
+				httpServer.Client(),
+			).
+			Error(),
+		)
+		assert.Check(t, cmp.DeepEqual(items, []int{1, 2, 3}))
+	})
+
+	t.Run("ko", func(t *testing.T) {
+		t.Run("on page failed", func(t *testing.T) {
+			assert.ErrorContains(t, doRequest("/items").
+				ReceiveJSONPaged(http.StatusOK,
+					func() any { return new(page) },
+					func(any) (string, error) { return "", errors.New("boom") },
+					httpServer.Client(),
+				).
+				Error(),
+				"unable to handle page: boom",
+			)
+		})
+
+		t.Run("next page status is unexpected", func(t *testing.T) {
+			assert.ErrorContains(t, doRequest("/items").
+				ReceiveJSONPaged(http.StatusOK,
+					func() any { return new(page) },
+					func(any) (string, error) { return "/notfound", nil },
+					httpServer.Client(),
+				).
+				Error(),
+				"unable to handle page 2",
+			)
+		})
+
+		t.Run("next page on another host", func(t *testing.T) {
+			otherServer, otherServerURL := newHTTPServerForTesting(t, func(rw http.ResponseWriter, r *http.Request) {
+				t.Error("request to another host made with headers", r.Header)
+			})
+
+			assert.ErrorContains(t, doRequest("/items").
+				ReceiveJSONPaged(http.StatusOK,
+					func() any { return new(page) },
+					func(any) (string, error) { return otherServerURL.String() + "/items/2", nil },
+					otherServer.Client(),
+				).
+				Error(),
+				"is not on the same scheme and host",
+			)
+		})
+
+		t.Run("too many pages", func(t *testing.T) {
+			var pages int
+
+			assert.ErrorContains(t, doRequest("/loop").
+				ReceiveJSONPaged(http.StatusOK,
+					func() any { return new(page) },
+					func(p any) (string, error) {
+						pages++
+						return p.(*page).Next, nil
+					},
+					httpServer.Client(),
+				).
+				Error(),
+				"too many pages, stopped after 1000 pages",
+			)
+			assert.Check(t, pages == ReceiveJSONPagedMaxPages)
+		})
+	})
+}
+
+func Test_ResponseBuilder_ReceiveMultipart(t *testing.T) {
+	httpServer, httpServerURL := newHTTPServerForTesting(t, func(rw http.ResponseWriter, r *http.Request) {
+		if contentType := r.URL.Query().Get("content-type"); contentType != "" {
+			rw.Header().Set("Content-Type", contentType)
+			rw.WriteHeader(http.StatusOK)
+			return
+		}
+
+		writer := multipart.NewWriter(rw)
+		rw.Header().Set("Content-Type", "multipart/mixed; boundary="+writer.Boundary())
+		rw.WriteHeader(http.StatusOK)
+
+		for _, content := range []string{`{"id":1}`, `{"id":2}`} {
+			part, err := writer.CreatePart(textproto.MIMEHeader{"Content-Type": {"application/json"}})
+			assert.NilError(t, err)
+			_, err = part.Write([]byte(content))
+			assert.NilError(t, err)
+			rw.(http.Flusher).Flush() // parts are streamed
+		}
+		assert.NilError(t, writer.Close())
+	})
+
+	doRequest := func(contentType string) *ResponseBuilder {
+		return NewRequest(http.MethodGet, httpServerURL.String()).
+			Client(httpServer.Client()).
+			SetQueryParam("content-type", contentType).
+			Do(context.Background())
+	}
+
+	t.Run("ok", func(t *testing.T) {
+		var parts []string
+		assert.NilError(t, doRequest("").BodySizeReadLimit(1024).ReceiveMultipart(http.StatusOK, func(part *multipart.Part) error {
+			assert.Check(t, part.Header.Get("Content-Type") == "application/json")
+			content, err := io.ReadAll(part)
+			parts = append(parts, string(content))
+			return err
+		}).Error())
+		assert.DeepEqual(t, parts, []string{`{"id":1}`, `{"id":2}`})
+	})
+
+	t.Run("ko", func(t *testing.T) {
+		noop := func(*multipart.Part) error { return nil }
+
+		t.Run("not a multipart", func(t *testing.T) {
+			assert.ErrorContains(t, doRequest("application/json").ReceiveMultipart(http.StatusOK, noop).Error(),
+				`Content-Type "application/json" is not a multipart with boundary`,
+			)
+			assert.ErrorContains(t, doRequest("multipart/mixed").ReceiveMultipart(http.StatusOK, noop).Error(),
+				`Content-Type "multipart/mixed" is not a multipart with boundary`,
+			)
+		})
+
+		t.Run("part handler fails", func(t *testing.T) {
+			anError := errors.New("an error")
+			err := doRequest("").BodySizeReadLimit(1024).ReceiveMultipart(http.StatusOK, func(*multipart.Part) error { return anError }).Error()
+			assert.ErrorIs(t, err, anError)
+			assert.ErrorContains(t, err, "unable to handle multipart part 0")
+		})
+
+		t.Run("body above read limit", func(t *testing.T) {
+			var parts int
+			err := doRequest("").BodySizeReadLimitStrict(100).ReceiveMultipart(http.StatusOK, func(part *multipart.Part) error {
+				parts++
+				_, err := io.Copy(io.Discard, part)
+				return err
+			}).Error()
+			assert.ErrorContains(t, err, "body is above read limit 100")
+			assert.Check(t, parts < 2)
+		})
+	})
+}
+
+func Test_ResponseBuilder_Trailers(t *testing.T) {
+	httpServer, httpServerURL := newHTTPServerForTesting(t, func(rw http.ResponseWriter, r *http.Request) {
+		rw.Header().Set("Trailer", "Checksum")
+		rw.WriteHeader(http.StatusOK)
+		_, err := rw.Write([]byte("hello world"))
+		assert.NilError(t, err)
+		rw.Header().Set("Checksum", "42")
+	})
+
+	resp := NewRequest(http.MethodGet, httpServerURL.String()).Client(httpServer.Client()).Do(context.Background())
+	assert.NilError(t, resp.BodySizeReadLimit(1024).OnStatus(http.StatusOK, func(r *http.Response) error {
+		assert.Check(t, resp.Trailers().Get("Checksum") == "")
+		_, err := io.Copy(io.Discard, r.Body)
+		assert.NilError(t, err)
+		assert.Check(t, resp.Trailers().Get("Checksum") == "42")
+		return nil
+	}).Error())
+	assert.Check(t, resp.Trailers().Get("Checksum") == "42")
+
+	assert.Check(t, newResponse().Trailers() == nil)
+}
+
+func Test_ResponseBuilder_BytesRead(t *testing.T) {
+	httpServer, httpServerURL := newHTTPServerForTesting(t, func(rw http.ResponseWriter, r *http.Request) {
+		rw.WriteHeader(http.StatusOK)
+		_, err := rw.Write([]byte(`{"hello":"world"}`))
+		assert.NilError(t, err)
+	})
+
+	t.Run("body handled", func(t *testing.T) {
+		var body map[string]string
+
+		resp := NewRequest(http.MethodGet, httpServerURL.String()).
+			Client(httpServer.Client()).
+			Do(context.Background()).
+			OnStatus(http.StatusOK, func(resp *http.Response) error {
+				raw, err := io.ReadAll(resp.Body)
+				if err != nil {
+					return err
+				}
+				return json.Unmarshal(raw, &body)
+			})
+		assert.Check(t, resp.BytesRead() == 0)
+		assert.NilError(t, resp.Error())
+		assert.Check(t, cmp.Equal(resp.BytesRead(), int64(len(`{"hello":"world"}`))))
+	})
+
+	t.Run("body buffered and read twice", func(t *testing.T) {
+		resp := NewRequest(http.MethodGet, httpServerURL.String()).
+			Client(httpServer.Client()).
+			Do(context.Background()).
+			BufferBody().
+			OnStatus(http.StatusOK, func(resp *http.Response) error {
+				for i := 0; i < 2; i++ {
+					if _, err := io.ReadAll(resp.Body); err != nil {
+						return err
+					}
+					if err := resp.Body.Close(); err != nil {
+						return err
+					}
+				}
+				return nil
+			})
+		assert.NilError(t, resp.Error())
+		assert.Check(t, cmp.Equal(resp.BytesRead(), int64(len(`{"hello":"world"}`))))
+	})
+
+	t.Run("body not handled", func(t *testing.T) {
+		resp := NewRequest(http.MethodGet, httpServerURL.String()).
+			Client(httpServer.Client()).
+			Do(context.Background()).
+			SuccessOnStatus(http.StatusOK)
+		assert.NilError(t, resp.Error())
+		assert.Check(t, resp.BytesRead() == 0)
+	})
+
+	t.Run("body not handled but buffered", func(t *testing.T) {
+		resp := NewRequest(http.MethodGet, httpServerURL.String()).
+			Client(httpServer.Client()).
+			Do(context.Background()).
+			BufferBody().
+			SuccessOnStatus(http.StatusOK)
+		assert.NilError(t, resp.Error())
+		assert.Check(t, cmp.Equal(resp.BytesRead(), int64(len(`{"hello":"world"}`))))
+	})
+}
+
+func Test_DecodeJSON(t *testing.T) {
+	type page[T any] struct {
+		Items []T `json:"items"`
+		Next  string
+	}
+
+	httpServer, httpServerURL := newHTTPServerForTesting(t, func(rw http.ResponseWriter, r *http.Request) {
+		rw.WriteHeader(http.StatusOK)
+		_, err := rw.Write([]byte(`{"items":["foo","bar"],"next":"/2"}`))
+		assert.NilError(t, err)
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		value, err := DecodeJSON[page[string]](NewRequest(http.MethodGet, httpServerURL.String()).
+			Client(httpServer.Client()).
+			Do(context.Background()),
+			http.StatusOK,
+		)
+		assert.NilError(t, err)
+		assert.Check(t, cmp.DeepEqual(value, page[string]{Items: []string{"foo", "bar"}, Next: "/2"}))
+	})
+
+	t.Run("ko", func(t *testing.T) {
+		value, err := DecodeJSON[page[int]](NewRequest(http.MethodGet, httpServerURL.String()).
+			Client(httpServer.Client()).
+			Do(context.Background()),
+			http.StatusOK,
+		)
+		assert.ErrorContains(t, err, "unable to parse JSON response body")
+		assert.Check(t, cmp.DeepEqual(value, page[int]{}))
+	})
+}
+
+func Test_ResponseBuilder_Result(t *testing.T) {
+	httpServer, httpServerURL := newHTTPServerForTesting(t, func(rw http.ResponseWriter, r *http.Request) {
+		rw.WriteHeader(http.StatusTeapot)
+		_, err := rw.Write([]byte("short and stout"))
+		assert.NilError(t, err)
+	})
+
+	t.Run("handled status", func(t *testing.T) {
+		result, err := NewRequest(http.MethodGet, httpServerURL.String()).
+			Client(httpServer.Client()).
+			Do(context.Background()).
+			SuccessOnStatus(http.StatusTeapot).
+			Result()
+		assert.NilError(t, err)
+		assert.Check(t, cmp.DeepEqual(result, &ResponseResult{StatusCode: http.StatusTeapot, Handled: true}))
+	})
+
+	t.Run("unhandled status", func(t *testing.T) {
+		result, err := NewRequest(http.MethodGet, httpServerURL.String()).
+			Client(httpServer.Client()).
+			Do(context.Background()).
+			BufferBody().
+			SuccessOnStatus(http.StatusOK).
+			Result()
+		assert.ErrorContains(t, err, "unhandled request status")
+		assert.Check(t, cmp.DeepEqual(result, &ResponseResult{
+			StatusCode: http.StatusTeapot,
+			Handled:    false,
+			Body:       []byte("short and stout"),
+		}))
+	})
+
+	t.Run("no response", func(t *testing.T) {
+		result, err := NewRequest(`\`, httpServerURL.String()).Do(context.Background()).Result()
+		assert.ErrorContains(t, err, "unable to create request")
+		assert.Check(t, result == nil)
+	})
+}
+
+func Test_ResponseBuilder_Snapshot(t *testing.T) {
+	httpServer, httpServerURL := newHTTPServerForTesting(t, func(rw http.ResponseWriter, r *http.Request) {
+		rw.Header().Set("Hello", "world")
+		rw.WriteHeader(http.StatusOK)
+		_, err := rw.Write([]byte(`{"hello":"world"}`))
+		assert.NilError(t, err)
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		var body map[string]string
+
+		resp := NewRequest(http.MethodGet, httpServerURL.String()).Client(httpServer.Client()).Do(context.Background())
+
+		snapshot, err := resp.Snapshot()
+		assert.NilError(t, err)
+		assert.Check(t, snapshot.StatusCode == http.StatusOK)
+		assert.Check(t, snapshot.Header.Get("Hello") == "world")
+		assert.Check(t, cmp.Equal(string(snapshot.Body), `{"hello":"world"}`))
+
+		assert.NilError(t, resp.ReceiveJSON(http.StatusOK, &body).Error())
+		assert.Check(t, cmp.DeepEqual(body, map[string]string{"hello": "world"}))
+	})
+
+	t.Run("ko", func(t *testing.T) {
+		t.Run("builder error", func(t *testing.T) {
+			snapshot, err := NewRequest(`\`, httpServerURL.String()).Do(context.Background()).Snapshot()
+			assert.ErrorContains(t, err, "unable to create request")
+			assert.Check(t, snapshot == nil)
+		})
+
+		t.Run("body above read limit", func(t *testing.T) {
+			snapshot, err := NewRequest(http.MethodGet, httpServerURL.String()).
+				Client(httpServer.Client()).
+				Do(context.Background()).
+				BodySizeReadLimit(2).
+				Snapshot()
+			assert.ErrorContains(t, err, "content length 17 is above read limit 2")
+			assert.Check(t, snapshot == nil)
+		})
+	})
+}
+
 func Test_ResponseBuilder_Error(t *testing.T) {
 	httpServer, httpServerURL := newHTTPServerForTesting(t, func(rw http.ResponseWriter, r *http.Request) {
 		switch r.Method {
@@ -298,3 +1418,59 @@ func (s *spyReadCloser) Close() error {
 	defer func() { s.closeCallCount++ }()
 	return s.readCloser.Close()
 }
+
+func Test_ResponseBuilder_MustError(t *testing.T) {
+	httpServer, httpServerURL := newHTTPServerForTesting(t, func(rw http.ResponseWriter, r *http.Request) {
+		rw.WriteHeader(http.StatusTeapot)
+	})
+
+	doRequest := func() *ResponseBuilder {
+		return NewRequest(http.MethodGet, httpServerURL.String()).Client(httpServer.Client()).Do(context.Background())
+	}
+
+	doRequest().SuccessOnStatus(http.StatusTeapot).MustError()
+
+	defer func() {
+		err, isErr := recover().(error)
+		assert.Assert(t, isErr)
+		assert.ErrorContains(t, err, "unhandled request status")
+	}()
+	doRequest().MustError()
+}
+
+// newResponseBuilderForTesting creates a response builder on a response with the provided status, headers, and body,
+// whose content length is set to the body length.
+func newResponseBuilderForTesting(status int, header http.Header, body []byte) *ResponseBuilder {
+	if header == nil {
+		header = make(http.Header)
+	}
+
+	responseBuilder := newResponse()
+	responseBuilder.resp = &http.Response{
+		StatusCode:    status,
+		ContentLength: int64(len(body)),
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		Request:       httptest.NewRequest(http.MethodGet, "/", nil),
+	}
+	return responseBuilder
+}
+
+// newEchoResponseForTesting starts a server answering with a 200 status, the Content-Type header and the body provided
+// in the request "content-type" and "body" query params, and returns a function requesting it.
+func newEchoResponseForTesting(t *testing.T) func(contentType, body string) *ResponseBuilder {
+	httpServer, httpServerURL := newHTTPServerForTesting(t, func(rw http.ResponseWriter, r *http.Request) {
+		rw.Header().Set("Content-Type", r.URL.Query().Get("content-type"))
+		rw.WriteHeader(http.StatusOK)
+		_, err := rw.Write([]byte(r.URL.Query().Get("body")))
+		assert.Check(t, err)
+	})
+
+	return func(contentType, body string) *ResponseBuilder {
+		return NewRequest(http.MethodGet, httpServerURL.String()).
+			Client(httpServer.Client()).
+			SetQueryParam("content-type", contentType).
+			SetQueryParam("body", body).
+			Do(context.Background())
+	}
+}
@@ -0,0 +1,24 @@
+// Package charset provides a httpclient.CharsetReaderFunc supporting the charsets of the WHATWG Encoding Standard.
+// It lives in its own package for golang.org/x/text to only be a dependency of the programs using it.
+package charset
+
+import (
+	"fmt"
+	"io"
+
+	"golang.org/x/text/encoding/htmlindex"
+
+	"github.com/krostar/httpclient"
+)
+
+var _ httpclient.CharsetReaderFunc = Reader
+
+// Reader converts the provided input, encoded with the provided charset, to an UTF-8 reader.
+// It is meant to be provided to the response builder methods that read text, like ResponseBuilder.ReceiveString.
+func Reader(charset string, input io.Reader) (io.Reader, error) {
+	enc, err := htmlindex.Get(charset)
+	if err != nil {
+		return nil, fmt.Errorf("unsupported charset %q: %v", charset, err)
+	}
+	return enc.NewDecoder().Reader(input), nil
+}
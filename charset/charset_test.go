@@ -0,0 +1,88 @@
+package charset
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/krostar/httpclient"
+)
+
+func Test_Reader(t *testing.T) {
+	httpServer := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.Header().Set("Content-Type", r.URL.Query().Get("content-type"))
+		rw.WriteHeader(http.StatusOK)
+		_, err := rw.Write([]byte("caf\xe9 \x80")) // café € in windows-1252
+		assert.Check(t, err == nil)
+	}))
+	defer httpServer.Close()
+
+	receiveString := func(contentType string, charsetReader httpclient.CharsetReaderFunc) (string, error) {
+		var dest string
+		err := httpclient.NewRequest(http.MethodGet, httpServer.URL).
+			Client(httpServer.Client()).
+			SetQueryParam("content-type", contentType).
+			Do(context.Background()).
+			BodySizeReadLimit(1024).
+			ReceiveString(http.StatusOK, &dest, charsetReader).
+			Error()
+		return dest, err
+	}
+
+	t.Run("ok", func(t *testing.T) {
+		body, err := receiveString("text/plain; charset=windows-1252", Reader)
+		assert.NilError(t, err)
+		assert.Equal(t, body, "café €")
+	})
+
+	t.Run("defaults to utf-8", func(t *testing.T) {
+		body, err := receiveString("text/plain", Reader)
+		assert.NilError(t, err)
+		assert.Equal(t, body, "caf\xe9 \x80")
+	})
+
+	t.Run("unsupported charset", func(t *testing.T) {
+		_, err := receiveString("text/plain; charset=unknown", Reader)
+		assert.ErrorContains(t, err, `unsupported charset "unknown"`)
+	})
+
+	t.Run("without charset reader", func(t *testing.T) {
+		_, err := receiveString("text/plain; charset=windows-1252", nil)
+		assert.ErrorContains(t, err, `unsupported charset "windows-1252"`)
+	})
+}
+
+func Test_Reader_receiveJSONWithCharset(t *testing.T) {
+	httpServer := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.Header().Set("Content-Type", r.URL.Query().Get("content-type"))
+		rw.WriteHeader(http.StatusOK)
+		_, err := rw.Write([]byte("{\"hello\":\"caf\xe9\"}")) // café in ISO-8859-1
+		assert.Check(t, err == nil)
+	}))
+	defer httpServer.Close()
+
+	receiveJSON := func(contentType string) (map[string]string, error) {
+		var dest map[string]string
+		err := httpclient.NewRequest(http.MethodGet, httpServer.URL).
+			Client(httpServer.Client()).
+			SetQueryParam("content-type", contentType).
+			Do(context.Background()).
+			ReceiveJSONWithCharset(http.StatusOK, &dest, Reader).
+			Error()
+		return dest, err
+	}
+
+	t.Run("ok", func(t *testing.T) {
+		body, err := receiveJSON("application/json; charset=ISO-8859-1")
+		assert.NilError(t, err)
+		assert.DeepEqual(t, body, map[string]string{"hello": "café"})
+	})
+
+	t.Run("unknown charset", func(t *testing.T) {
+		_, err := receiveJSON("application/json; charset=foo")
+		assert.ErrorContains(t, err, `unable to read body with charset "foo"`)
+	})
+}
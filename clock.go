@@ -0,0 +1,20 @@
+package httpclient
+
+import (
+	"time"
+)
+
+// Clock provides the current time and timers to doer wrappers and helpers that wait, like DoerWrapFaultInjection
+// or ExponentialBackoff.Wait. It allows to control time in tests, see httpclienttest.FakeClock.
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+// RealClock returns a clock that relies on the time package.
+func RealClock() Clock { return realClock{} }
+
+type realClock struct{}
+
+func (realClock) Now() time.Time                         { return time.Now() }
+func (realClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
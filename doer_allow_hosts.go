@@ -0,0 +1,153 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// ErrHostNotAllowed is returned by doers wrapped with DoerWrapAllowHosts for requests to a host that is not allowed.
+var ErrHostNotAllowed = errors.New("host not allowed")
+
+// DoerWrapAllowHosts wraps the provided doer by rejecting, with ErrHostNotAllowed, requests whose host is not in the allowed list.
+// Allowed hosts are compared case-insensitively with the request host, with or without port.
+// Allowed host names are also resolved, and rejected if they resolve to a private, loopback, or link-local address,
+// which guards against server-side request forgery; IP addresses explicitly allowed are not rejected.
+//
+// If the provided doer is an *http.Client, the returned doer uses a copy of it which checks redirects the same way,
+// and, if its transport is an *http.Transport, which checks the address connected to for allowed host names,
+// as it may differ from the one resolved beforehand (DNS rebinding); the check is skipped for connections to a proxy.
+// Other doers are only protected by the check of the request host, against a static allow-list.
+func DoerWrapAllowHosts(doer Doer, allowed []string) Doer {
+	allowedHosts := make(map[string]struct{}, len(allowed))
+	for _, host := range allowed {
+		allowedHosts[strings.ToLower(host)] = struct{}{}
+	}
+
+	w := &doerWrapAllowHosts{
+		doer:         doer,
+		allowed:      allowedHosts,
+		lookupIPAddr: net.DefaultResolver.LookupIPAddr,
+	}
+
+	if client, isHTTPClient := doer.(*http.Client); isHTTPClient {
+		w.doer = w.client(client)
+	}
+
+	return w
+}
+
+type doerWrapAllowHosts struct {
+	doer         Doer
+	allowed      map[string]struct{}
+	lookupIPAddr func(ctx context.Context, host string) ([]net.IPAddr, error)
+}
+
+func (w doerWrapAllowHosts) Do(req *http.Request) (*http.Response, error) {
+	if err := w.checkHost(req.Context(), req.URL.Host, req.URL.Hostname()); err != nil {
+		return nil, err
+	}
+	return w.doer.Do(req)
+}
+
+func (w doerWrapAllowHosts) isAllowed(host, hostname string) bool {
+	_, hostAllowed := w.allowed[strings.ToLower(host)]
+	_, hostnameAllowed := w.allowed[strings.ToLower(hostname)]
+	return hostAllowed || hostnameAllowed
+}
+
+func (w doerWrapAllowHosts) checkHost(ctx context.Context, host, hostname string) error {
+	if !w.isAllowed(host, hostname) {
+		return fmt.Errorf("%w: %s", ErrHostNotAllowed, host)
+	}
+
+	if net.ParseIP(hostname) != nil {
+		return nil
+	}
+
+	addrs, err := w.lookupIPAddr(ctx, hostname)
+	if err != nil {
+		return fmt.Errorf("unable to resolve host %s: %w", hostname, err)
+	}
+
+	for _, addr := range addrs {
+		if isNonPublicIP(addr.IP) {
+			return fmt.Errorf("%w: %s resolves to non-public address %s", ErrHostNotAllowed, host, addr.IP)
+		}
+	}
+
+	return nil
+}
+
+// client returns a copy of the provided client which checks redirects, and connections if possible, against allowed hosts.
+func (w *doerWrapAllowHosts) client(client *http.Client) *http.Client {
+	checkRedirect := client.CheckRedirect
+	if checkRedirect == nil {
+		checkRedirect = func(_ *http.Request, via []*http.Request) error {
+			if len(via) >= 10 { //nolint:gomnd // same limit as the http package default
+				return errors.New("stopped after 10 redirects")
+			}
+			return nil
+		}
+	}
+
+	allowHostsClient := *client
+	allowHostsClient.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if err := w.checkHost(req.Context(), req.URL.Host, req.URL.Hostname()); err != nil {
+			return err
+		}
+		return checkRedirect(req, via)
+	}
+
+	roundTripper := client.Transport
+	if roundTripper == nil {
+		roundTripper = http.DefaultTransport
+	}
+
+	if transport, isHTTPTransport := roundTripper.(*http.Transport); isHTTPTransport {
+		transport = transport.Clone()
+
+		dialContext := transport.DialContext
+		if dialContext == nil {
+			dialContext = new(net.Dialer).DialContext
+		}
+		transport.DialContext = w.dialContext(dialContext)
+		if transport.DialTLSContext != nil {
+			transport.DialTLSContext = w.dialContext(transport.DialTLSContext)
+		}
+
+		allowHostsClient.Transport = transport
+	}
+
+	return &allowHostsClient
+}
+
+// dialContext wraps the provided dial function by checking that connections made to allowed host names
+// are not made to a private, loopback, or link-local address. The connection is closed before anything is sent otherwise.
+func (w *doerWrapAllowHosts) dialContext(dial func(ctx context.Context, network, addr string) (net.Conn, error)) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		conn, err := dial(ctx, network, addr)
+		if err != nil {
+			return nil, err
+		}
+
+		hostname, _, err := net.SplitHostPort(addr)
+		if err != nil || net.ParseIP(hostname) != nil || !w.isAllowed(addr, hostname) {
+			return conn, nil // explicitly allowed IP address or proxy
+		}
+
+		if tcpAddr, isTCPAddr := conn.RemoteAddr().(*net.TCPAddr); isTCPAddr && isNonPublicIP(tcpAddr.IP) {
+			_ = conn.Close()
+			return nil, fmt.Errorf("%w: %s connected to non-public address %s", ErrHostNotAllowed, addr, tcpAddr.IP)
+		}
+
+		return conn, nil
+	}
+}
+
+func isNonPublicIP(ip net.IP) bool {
+	return ip.IsPrivate() || ip.IsLoopback() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsUnspecified()
+}
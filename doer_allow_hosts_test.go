@@ -0,0 +1,99 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func Test_DoerWrapAllowHosts(t *testing.T) {
+	httpServer, httpServerURL := newHTTPServerForTesting(t, func(rw http.ResponseWriter, r *http.Request) {
+		if location := r.URL.Query().Get("redirect"); location != "" {
+			http.Redirect(rw, r, location, http.StatusFound)
+			return
+		}
+		rw.WriteHeader(http.StatusOK)
+	})
+
+	newDoerWithClient := func(client *http.Client, allowed ...string) Doer {
+		doer := DoerWrapAllowHosts(client, allowed).(*doerWrapAllowHosts)
+		doer.lookupIPAddr = func(_ context.Context, host string) ([]net.IPAddr, error) {
+			switch host {
+			case "public.example":
+				return []net.IPAddr{{IP: net.ParseIP("93.184.216.34")}}, nil
+			case "internal.example":
+				return []net.IPAddr{{IP: net.ParseIP("93.184.216.34")}, {IP: net.ParseIP("10.0.0.1")}}, nil
+			case "localhost":
+				return []net.IPAddr{{IP: net.ParseIP("::1")}}, nil
+			default:
+				return nil, errors.New("no such host")
+			}
+		}
+		return doer
+	}
+
+	newDoer := func(allowed ...string) Doer { return newDoerWithClient(httpServer.Client(), allowed...) }
+
+	t.Run("allowed host", func(t *testing.T) {
+		resp, err := newDoer(httpServerURL.Hostname()).Do(newHTTPRequestForTesting(t, http.MethodGet, httpServerURL.String(), nil))
+		assert.NilError(t, err)
+		assert.NilError(t, resp.Body.Close())
+		assert.Equal(t, resp.StatusCode, http.StatusOK)
+
+		doer := newDoer("PUBLIC.example").(*doerWrapAllowHosts)
+		assert.NilError(t, doer.checkHost(context.Background(), "public.example:8080", "public.example"))
+	})
+
+	t.Run("host not allowed", func(t *testing.T) {
+		resp, err := newDoer("public.example").Do(newHTTPRequestForTesting(t, http.MethodGet, httpServerURL.String(), nil))
+		assert.ErrorIs(t, err, ErrHostNotAllowed)
+		assert.Check(t, resp == nil)
+
+		doer := newDoer("public.example:8080").(*doerWrapAllowHosts)
+		assert.ErrorIs(t, doer.checkHost(context.Background(), "public.example:8081", "public.example"), ErrHostNotAllowed)
+	})
+
+	t.Run("allowed host resolving to a private address", func(t *testing.T) {
+		doer := newDoer("internal.example", "localhost").(*doerWrapAllowHosts)
+		assert.ErrorIs(t, doer.checkHost(context.Background(), "internal.example", "internal.example"), ErrHostNotAllowed)
+		assert.ErrorContains(t, doer.checkHost(context.Background(), "localhost", "localhost"), "localhost resolves to non-public address ::1")
+	})
+
+	t.Run("redirect to a host not allowed", func(t *testing.T) {
+		redirectURL := httpServerURL
+		redirectURL.Host = "localhost:" + httpServerURL.Port()
+		endpoint := httpServerURL.String() + "/?redirect=" + url.QueryEscape(redirectURL.String())
+
+		// the client returns the redirect response, whose body is closed, along with the redirect check error
+		resp, err := newDoer(httpServerURL.Hostname()).Do(newHTTPRequestForTesting(t, http.MethodGet, endpoint, nil))
+		assert.ErrorIs(t, err, ErrHostNotAllowed)
+		assert.Equal(t, resp.StatusCode, http.StatusFound)
+
+		resp, err = newDoer(httpServerURL.Hostname(), "localhost").Do(newHTTPRequestForTesting(t, http.MethodGet, endpoint, nil))
+		assert.ErrorContains(t, err, "resolves to non-public address ::1")
+		assert.Equal(t, resp.StatusCode, http.StatusFound)
+	})
+
+	t.Run("allowed host connecting to a private address", func(t *testing.T) {
+		transport := httpServer.Client().Transport.(*http.Transport).Clone()
+		transport.DialContext = func(ctx context.Context, network, _ string) (net.Conn, error) {
+			return new(net.Dialer).DialContext(ctx, network, httpServer.Listener.Addr().String())
+		}
+
+		doer := newDoerWithClient(&http.Client{Transport: transport}, "public.example")
+		resp, err := doer.Do(newHTTPRequestForTesting(t, http.MethodGet, "http://public.example/", nil))
+		assert.ErrorIs(t, err, ErrHostNotAllowed)
+		assert.ErrorContains(t, err, "public.example:80 connected to non-public address 127.0.0.1")
+		assert.Check(t, resp == nil)
+	})
+
+	t.Run("unresolvable host", func(t *testing.T) {
+		doer := newDoer("unknown.example").(*doerWrapAllowHosts)
+		assert.ErrorContains(t, doer.checkHost(context.Background(), "unknown.example", "unknown.example"), "unable to resolve host unknown.example: no such host")
+	})
+}
@@ -0,0 +1,40 @@
+package httpclient
+
+import (
+	"net/http"
+)
+
+// DoerWrapCookieJar wraps the provided doer by sending the cookies stored in the provided jar with each request,
+// and storing the cookies set by each response in the jar. It is useful for doers that are not *http.Client,
+// as http.Client.Jar handles cookies by itself.
+func DoerWrapCookieJar(doer Doer, jar http.CookieJar) Doer {
+	return &doerWrapCookieJar{
+		doer: doer,
+		jar:  jar,
+	}
+}
+
+type doerWrapCookieJar struct {
+	doer Doer
+	jar  http.CookieJar
+}
+
+func (w doerWrapCookieJar) Do(req *http.Request) (*http.Response, error) {
+	if cookies := w.jar.Cookies(req.URL); len(cookies) > 0 {
+		req = req.Clone(req.Context())
+		for _, cookie := range cookies {
+			req.AddCookie(cookie)
+		}
+	}
+
+	resp, err := w.doer.Do(req)
+	if err != nil {
+		return resp, err
+	}
+
+	if cookies := resp.Cookies(); len(cookies) > 0 {
+		w.jar.SetCookies(req.URL, cookies)
+	}
+
+	return resp, nil
+}
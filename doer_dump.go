@@ -0,0 +1,47 @@
+package httpclient
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+)
+
+// DoerWrapDump wraps the provided doer by writing a dump of the request and response to the provided writer,
+// followed by a separator line. It is useful for local debugging, with os.Stderr or a file as writer.
+// If the request fails, the error is dumped instead of the response.
+// Each call is written using a single Write call. If the provided writer is nil, the provided doer is returned as is.
+func DoerWrapDump(doer Doer, w io.Writer) Doer {
+	if w == nil {
+		return doer
+	}
+
+	return &doerWrapDump{
+		doer: doer,
+		w:    w,
+	}
+}
+
+type doerWrapDump struct {
+	doer Doer
+	w    io.Writer
+}
+
+func (w doerWrapDump) Do(req *http.Request) (*http.Response, error) {
+	var dump bytes.Buffer
+
+	dump.Write(dumpRequest(req))
+	resp, err := w.doer.Do(req)
+	switch {
+	case err != nil:
+		dump.WriteString("\n\nerror: ")
+		dump.WriteString(err.Error())
+	case resp != nil:
+		dump.WriteString("\n\n")
+		dump.Write(dumpResponse(resp))
+	}
+	dump.WriteString("\n----------------\n")
+
+	_, _ = w.w.Write(dump.Bytes())
+
+	return resp, err
+}
@@ -1,7 +1,9 @@
 package httpclient
 
 import (
+	"crypto/rand"
 	"encoding/base64"
+	"encoding/hex"
 	"net/http"
 	"net/http/httputil"
 )
@@ -12,57 +14,76 @@ func DoerWrapDumpB64(doer Doer, dumpFunc func(requestB64, responseB64 string)) D
 		dumpFunc = func(string, string) {}
 	}
 
-	doer = &doerWrapDump64{
+	return DoerWrapDumpB64WithID(doer, func(_, requestB64, responseB64 string) {
+		dumpFunc(requestB64, responseB64)
+	})
+}
+
+// DoerWrapDumpB64WithID is like DoerWrapDumpB64, but the callback is also called with an id generated for each call.
+// It helps to correlate requests and responses dumps, for instance when they are logged separately.
+func DoerWrapDumpB64WithID(doer Doer, dumpFunc func(id, requestB64, responseB64 string)) Doer {
+	if dumpFunc == nil {
+		dumpFunc = func(string, string, string) {}
+	}
+
+	return &doerWrapDump64{
 		doer: doer,
 		dump: dumpFunc,
 	}
-
-	return doer
 }
 
 type doerWrapDump64 struct {
 	doer Doer
-	dump func(string, string)
+	dump func(string, string, string)
 }
 
 func (w doerWrapDump64) Do(req *http.Request) (*http.Response, error) {
+	id := w.id()
 	requestB64 := w.request(req)
 	resp, err := w.doer.Do(req)
 	responseB64 := w.response(resp)
 
-	w.dump(requestB64, responseB64)
+	w.dump(id, requestB64, responseB64)
 
 	return resp, err
 }
 
+func (doerWrapDump64) id() string {
+	raw := make([]byte, 8) //nolint:gomnd // 64 bits are enough to correlate dumps
+	if _, err := rand.Read(raw); err != nil {
+		return "unable to generate id: " + err.Error()
+	}
+	return hex.EncodeToString(raw)
+}
+
 func (doerWrapDump64) request(req *http.Request) string {
 	if req == nil {
 		return ""
 	}
-
-	var out []byte
-
-	if dump, err := httputil.DumpRequestOut(req, true); err == nil {
-		out = dump
-	} else {
-		out = []byte("unable to dump request: " + err.Error())
-	}
-
-	return base64.StdEncoding.EncodeToString(out)
+	return base64.StdEncoding.EncodeToString(dumpRequest(req))
 }
 
 func (doerWrapDump64) response(resp *http.Response) string {
 	if resp == nil {
 		return ""
 	}
+	return base64.StdEncoding.EncodeToString(dumpResponse(resp))
+}
 
-	var out []byte
-
-	if dump, err := httputil.DumpResponse(resp, true); err == nil {
-		out = dump
-	} else {
-		out = []byte("unable to dump response:" + err.Error())
+// dumpRequest dumps the provided request, or the reason why the request can't be dumped.
+func dumpRequest(req *http.Request) []byte {
+	dump, err := httputil.DumpRequestOut(req, true)
+	if err != nil {
+		return []byte("unable to dump request: " + err.Error())
 	}
+	return dump
+}
 
-	return base64.StdEncoding.EncodeToString(out)
+// dumpResponse dumps the provided response, or the reason why the response can't be dumped.
+func dumpResponse(resp *http.Response) []byte {
+	dump, err := httputil.DumpResponse(resp, true)
+	if err != nil {
+		return []byte("unable to dump response:" + err.Error())
+	}
+	return dump
 }
@@ -3,7 +3,9 @@ package httpclient
 import (
 	"encoding/base64"
 	"net/http"
+	"strconv"
 	"strings"
+	"sync"
 	"testing"
 
 	"gotest.tools/v3/assert"
@@ -37,3 +39,51 @@ func Test_DoerWrapDumpB64(t *testing.T) {
 	assert.Equal(t, resp.StatusCode, http.StatusTeapot)
 	assert.NilError(t, resp.Body.Close())
 }
+
+func Test_DoerWrapDumpB64WithID(t *testing.T) {
+	httpServer, httpServerURL := newHTTPServerForTesting(t, func(rw http.ResponseWriter, r *http.Request) {
+		rw.WriteHeader(http.StatusOK)
+		_, err := rw.Write([]byte("response to " + r.URL.Path))
+		assert.NilError(t, err)
+	})
+
+	var (
+		m     sync.Mutex
+		dumps = make(map[string][2]string)
+	)
+
+	doer := DoerWrapDumpB64WithID(httpServer.Client(), func(id, req, resp string) {
+		reqDecoded, err := base64.StdEncoding.DecodeString(req)
+		assert.Check(t, err == nil)
+		respDecoded, err := base64.StdEncoding.DecodeString(resp)
+		assert.Check(t, err == nil)
+
+		m.Lock()
+		defer m.Unlock()
+		_, exists := dumps[id]
+		assert.Check(t, !exists, "id %s is not unique", id)
+		dumps[id] = [2]string{string(reqDecoded), string(respDecoded)}
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		req := newHTTPRequestForTesting(t, http.MethodGet, httpServerURL.String()+"/"+strconv.Itoa(i), nil)
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := doer.Do(req)
+			assert.Check(t, err == nil)
+			assert.Check(t, resp.Body.Close() == nil)
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, len(dumps), 10)
+	for id, dump := range dumps {
+		assert.Check(t, len(id) == 16)
+
+		path := strings.TrimPrefix(strings.SplitN(dump[0], " ", 3)[1], "/")
+		assert.Check(t, strings.HasSuffix(dump[1], "response to /"+path), "request and response dumps of id %s do not match", id)
+	}
+}
@@ -0,0 +1,66 @@
+package httpclient
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/assert/cmp"
+)
+
+func Test_DoerWrapDump(t *testing.T) {
+	httpServer, httpServerURL := newHTTPServerForTesting(t, func(rw http.ResponseWriter, r *http.Request) {
+		rw.WriteHeader(http.StatusTeapot)
+		_, err := rw.Write([]byte(`"hello world"`))
+		assert.NilError(t, err)
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		var output bytes.Buffer
+
+		req := newHTTPRequestForTesting(t, http.MethodPost, httpServerURL.String()+"/foo", strings.NewReader("hi!"))
+		resp, err := DoerWrapDump(httpServer.Client(), &output).Do(req)
+		assert.NilError(t, err)
+		assert.Equal(t, resp.StatusCode, http.StatusTeapot)
+
+		body, err := io.ReadAll(resp.Body)
+		assert.NilError(t, err)
+		assert.Check(t, cmp.Equal(string(body), `"hello world"`))
+		assert.NilError(t, resp.Body.Close())
+
+		dump := output.String()
+		assert.Check(t, cmp.Contains(dump, "POST /foo HTTP/1.1"))
+		assert.Check(t, cmp.Contains(dump, "hi!"))
+		assert.Check(t, cmp.Contains(dump, "HTTP/1.1 418 I'm a teapot"))
+		assert.Check(t, cmp.Contains(dump, `"hello world"`))
+		assert.Check(t, strings.Index(dump, "hi!") < strings.Index(dump, "HTTP/1.1 418"))
+		assert.Check(t, strings.HasSuffix(dump, "\n----------------\n"))
+	})
+
+	t.Run("request error", func(t *testing.T) {
+		var output bytes.Buffer
+
+		req := newHTTPRequestForTesting(t, http.MethodGet, httpServerURL.String()+"/foo", nil)
+		resp, err := DoerWrapDump(&doerFail{err: errors.New("boom")}, &output).Do(req)
+		assert.Error(t, err, "boom")
+		assert.Check(t, resp == nil)
+		assert.Check(t, cmp.Contains(output.String(), "GET /foo HTTP/1.1"))
+		assert.Check(t, cmp.Contains(output.String(), "\n\nerror: boom\n----------------\n"))
+		assert.Check(t, !strings.Contains(output.String(), "HTTP/1.1 418"))
+	})
+
+	t.Run("nil writer", func(t *testing.T) {
+		doer := httpServer.Client()
+		assert.Check(t, DoerWrapDump(doer, nil) == Doer(doer))
+
+		req := newHTTPRequestForTesting(t, http.MethodGet, httpServerURL.String(), nil)
+		resp, err := DoerWrapDump(doer, nil).Do(req)
+		assert.NilError(t, err)
+		assert.Equal(t, resp.StatusCode, http.StatusTeapot)
+		assert.NilError(t, resp.Body.Close())
+	})
+}
@@ -0,0 +1,87 @@
+package httpclient
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// FaultConfig defines the faults injected by DoerWrapFaultInjection.
+type FaultConfig struct {
+	// Probability is the probability, between 0 and 1, of a call to be faulted.
+	Probability float64
+	// Error is returned on faulted calls, if set.
+	Error error
+	// Status is the status of the response returned on faulted calls, if Error is unset.
+	// If both Error and Status are unset, a generic error is returned.
+	Status int
+	// Latency is added to every call, faulted or not.
+	Latency time.Duration
+	// Rand is used to decide whenever a call is faulted. Providing a seeded source makes faults deterministic.
+	// If unset, a source seeded with the current time is used.
+	Rand *rand.Rand
+	// Clock is used to wait for the latency. If unset, RealClock is used.
+	Clock Clock
+}
+
+// DoerWrapFaultInjection wraps the provided doer by injecting faults instead of calling the doer, as configured.
+// It is useful to test resilience mechanisms like retries or circuit breakers.
+func DoerWrapFaultInjection(doer Doer, cfg FaultConfig) Doer {
+	if cfg.Rand == nil {
+		cfg.Rand = rand.New(rand.NewSource(time.Now().UnixNano())) //nolint:gosec // no need for a cryptographically secure source
+	}
+
+	if cfg.Clock == nil {
+		cfg.Clock = RealClock()
+	}
+
+	return &doerWrapFaultInjection{
+		doer: doer,
+		cfg:  cfg,
+	}
+}
+
+type doerWrapFaultInjection struct {
+	doer Doer
+	cfg  FaultConfig
+	m    sync.Mutex // protects cfg.Rand which is not safe for concurrent use
+}
+
+func (w *doerWrapFaultInjection) Do(req *http.Request) (*http.Response, error) {
+	if w.cfg.Latency > 0 {
+		select {
+		case <-w.cfg.Clock.After(w.cfg.Latency):
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		}
+	}
+
+	w.m.Lock()
+	faulted := w.cfg.Rand.Float64() < w.cfg.Probability
+	w.m.Unlock()
+
+	if !faulted {
+		return w.doer.Do(req)
+	}
+
+	switch {
+	case w.cfg.Error != nil:
+		return nil, w.cfg.Error
+	case w.cfg.Status != 0:
+		return &http.Response{
+			Status:     fmt.Sprintf("%d %s", w.cfg.Status, http.StatusText(w.cfg.Status)),
+			StatusCode: w.cfg.Status,
+			Proto:      "HTTP/1.1",
+			ProtoMajor: 1,
+			ProtoMinor: 1,
+			Header:     make(http.Header),
+			Body:       http.NoBody,
+			Request:    req,
+		}, nil
+	default:
+		return nil, errors.New("injected fault")
+	}
+}
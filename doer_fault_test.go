@@ -0,0 +1,92 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net/http"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+)
+
+func Test_DoerWrapFaultInjection(t *testing.T) {
+	httpServer, httpServerURL := newHTTPServerForTesting(t, func(rw http.ResponseWriter, r *http.Request) {
+		rw.WriteHeader(http.StatusOK)
+	})
+
+	doRequest := func(t *testing.T, doer Doer) (*http.Response, error) {
+		resp, err := doer.Do(newHTTPRequestForTesting(t, http.MethodGet, httpServerURL.String(), nil))
+		if resp != nil {
+			assert.NilError(t, resp.Body.Close())
+		}
+		return resp, err
+	}
+
+	t.Run("probability 0 never injects faults", func(t *testing.T) {
+		doer := DoerWrapFaultInjection(httpServer.Client(), FaultConfig{
+			Probability: 0,
+			Error:       errors.New("boom"),
+			Rand:        rand.New(rand.NewSource(42)), //nolint:gosec // test
+		})
+
+		for i := 0; i < 10; i++ {
+			resp, err := doRequest(t, doer)
+			assert.NilError(t, err)
+			assert.Check(t, resp.StatusCode == http.StatusOK)
+		}
+	})
+
+	t.Run("probability 1 always injects faults", func(t *testing.T) {
+		t.Run("error", func(t *testing.T) {
+			anError := errors.New("boom")
+			doer := DoerWrapFaultInjection(httpServer.Client(), FaultConfig{Probability: 1, Error: anError})
+
+			for i := 0; i < 10; i++ {
+				resp, err := doRequest(t, doer)
+				assert.ErrorIs(t, err, anError)
+				assert.Check(t, resp == nil)
+			}
+		})
+
+		t.Run("status", func(t *testing.T) {
+			doer := DoerWrapFaultInjection(httpServer.Client(), FaultConfig{Probability: 1, Status: http.StatusServiceUnavailable})
+
+			for i := 0; i < 10; i++ {
+				resp, err := doRequest(t, doer)
+				assert.NilError(t, err)
+				assert.Check(t, resp.StatusCode == http.StatusServiceUnavailable)
+				assert.Check(t, resp.Status == "503 Service Unavailable")
+			}
+		})
+
+		t.Run("generic error", func(t *testing.T) {
+			resp, err := doRequest(t, DoerWrapFaultInjection(httpServer.Client(), FaultConfig{Probability: 1}))
+			assert.ErrorContains(t, err, "injected fault")
+			assert.Check(t, resp == nil)
+		})
+	})
+
+	t.Run("latency", func(t *testing.T) {
+		t.Run("added", func(t *testing.T) {
+			doer := DoerWrapFaultInjection(httpServer.Client(), FaultConfig{Latency: 20 * time.Millisecond})
+
+			start := time.Now()
+			_, err := doRequest(t, doer)
+			assert.NilError(t, err)
+			assert.Check(t, time.Since(start) >= 20*time.Millisecond)
+		})
+
+		t.Run("context is respected", func(t *testing.T) {
+			doer := DoerWrapFaultInjection(httpServer.Client(), FaultConfig{Latency: time.Hour})
+
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+
+			resp, err := doer.Do(newHTTPRequestForTesting(t, http.MethodGet, httpServerURL.String(), nil).WithContext(ctx))
+			assert.ErrorIs(t, err, context.Canceled)
+			assert.Check(t, resp == nil)
+		})
+	})
+}
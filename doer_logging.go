@@ -0,0 +1,68 @@
+package httpclient
+
+import (
+	"net/http"
+
+	"golang.org/x/exp/slog"
+)
+
+// DoerWrapLogging wraps the provided doer by logging each request with its method, url, response status, and duration.
+// Requests are logged at info level, or at error level if the request fails. The request label, if any, is logged as well.
+// Request and response bodies are not read, which makes it safe to use with streamed responses.
+// The url is logged redacted, without the userinfo password.
+// If the provided logger is nil, slog.Default() is used. Note that the logger is a golang.org/x/exp/slog logger,
+// not a log/slog one, as this module supports Go versions older than 1.21: a log/slog logger can't be provided.
+func DoerWrapLogging(doer Doer, logger *slog.Logger) Doer {
+	return DoerWrapLoggingWithClock(doer, logger, nil)
+}
+
+// DoerWrapLoggingWithClock is like DoerWrapLogging but measures requests duration with the provided clock.
+// If the provided clock is nil, RealClock is used.
+func DoerWrapLoggingWithClock(doer Doer, logger *slog.Logger, clock Clock) Doer {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	if clock == nil {
+		clock = RealClock()
+	}
+
+	return &doerWrapLogging{
+		doer:   doer,
+		logger: logger,
+		clock:  clock,
+	}
+}
+
+type doerWrapLogging struct {
+	doer   Doer
+	logger *slog.Logger
+	clock  Clock
+}
+
+func (w doerWrapLogging) Do(req *http.Request) (*http.Response, error) {
+	start := w.clock.Now()
+	resp, err := w.doer.Do(req)
+	elapsed := w.clock.Now().Sub(start)
+
+	attrs := []slog.Attr{
+		slog.String("method", req.Method),
+		slog.String("url", req.URL.Redacted()),
+		slog.Duration("elapsed", elapsed),
+	}
+
+	if label, found := LabelFromContext(req.Context()); found {
+		attrs = append(attrs, slog.String("label", label))
+	}
+
+	logger := w.logger.WithContext(req.Context())
+
+	if err != nil {
+		logger.LogAttrs(slog.LevelError, "http request failed", append(attrs, slog.Any(slog.ErrorKey, err))...)
+		return resp, err
+	}
+
+	logger.LogAttrs(slog.LevelInfo, "http request", append(attrs, slog.Int("status", resp.StatusCode))...)
+
+	return resp, nil
+}
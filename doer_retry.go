@@ -0,0 +1,116 @@
+package httpclient
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// RetryOptions defines how DoerWrapRetry retries requests.
+type RetryOptions struct {
+	// MaxAttempts is the maximum number of calls made for a request, including the first one. Lower than 1 means 1.
+	MaxAttempts int
+	// Backoff returns the delay to wait after the provided attempt, starting at 1, before retrying.
+	// If unset, requests are retried right away. See ExponentialBackoff.Backoff.
+	Backoff func(attempt int) time.Duration
+	// MaxElapsedTime is the total time budget, measured since the first attempt started, after which no retry is made:
+	// the last response is returned if waiting for the backoff delay would exceed it. Zero means no budget.
+	MaxElapsedTime time.Duration
+	// Retryable returns whether the request should be retried, given the response and error of the attempt.
+	// If unset, requests are retried on error and on 5xx statuses. See RetryOnStatus and the other predicates.
+	Retryable RetryPredicate
+	// Clock is used to wait between attempts. If unset, RealClock is used.
+	Clock Clock
+}
+
+// DoerWrapRetry wraps the provided doer by retrying requests, as configured.
+// The request body is rewound between attempts using req.GetBody. If the body can't be rewound, the response of the last attempt
+// is returned as is, or, if the attempt failed without response, an error is returned. The responses of the attempts that are retried are closed. Waiting between attempts stops when the request context is done.
+func DoerWrapRetry(doer Doer, opts RetryOptions) Doer {
+	if opts.MaxAttempts < 1 {
+		opts.MaxAttempts = 1
+	}
+
+	if opts.Backoff == nil {
+		opts.Backoff = func(int) time.Duration { return 0 }
+	}
+
+	if opts.Retryable == nil {
+		opts.Retryable = func(resp *http.Response, err error) bool {
+			return err != nil || (resp != nil && resp.StatusCode >= http.StatusInternalServerError)
+		}
+	}
+
+	if opts.Clock == nil {
+		opts.Clock = RealClock()
+	}
+
+	return &doerWrapRetry{
+		doer: doer,
+		opts: opts,
+	}
+}
+
+type doerWrapRetry struct {
+	doer Doer
+	opts RetryOptions
+}
+
+func (w *doerWrapRetry) Do(req *http.Request) (*http.Response, error) {
+	attemptReq := req
+	start := w.opts.Clock.Now()
+
+	for attempt := 1; ; attempt++ {
+		resp, err := w.doer.Do(attemptReq)
+		if attempt >= w.opts.MaxAttempts || !w.opts.Retryable(resp, err) {
+			return resp, err
+		}
+
+		delay := w.opts.Backoff(attempt)
+		if w.opts.MaxElapsedTime > 0 && w.opts.Clock.Now().Sub(start)+delay > w.opts.MaxElapsedTime {
+			return resp, err
+		}
+
+		rewound, rewindErr := rewindRequest(req)
+		switch {
+		case rewindErr != nil && resp != nil:
+			return resp, err
+		case rewindErr != nil:
+			return nil, fmt.Errorf("unable to retry request after attempt %d failed with %v: %w", attempt, err, rewindErr)
+		}
+		attemptReq = rewound
+
+		if resp != nil && resp.Body != nil {
+			_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4*KB)) //nolint:gomnd // drain to reuse the connection
+			_ = resp.Body.Close()
+		}
+
+		select {
+		case <-w.opts.Clock.After(delay):
+		case <-req.Context().Done():
+			return nil, fmt.Errorf("unable to retry request after attempt %d: %w", attempt, req.Context().Err())
+		}
+	}
+}
+
+// rewindRequest returns a copy of the provided request whose body is a new one, obtained using req.GetBody.
+func rewindRequest(req *http.Request) (*http.Request, error) {
+	rewound := req.Clone(req.Context())
+	if req.Body == nil || req.Body == http.NoBody {
+		return rewound, nil
+	}
+
+	if req.GetBody == nil {
+		return nil, errors.New("request body can't be rewound: request GetBody is unset")
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, fmt.Errorf("unable to rewind request body: %w", err)
+	}
+	rewound.Body = body
+
+	return rewound, nil
+}
@@ -0,0 +1,143 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/assert/cmp"
+)
+
+func Test_DoerWrapRetry(t *testing.T) {
+	var failures int32
+
+	httpServer, httpServerURL := newHTTPServerForTesting(t, func(rw http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		assert.Check(t, err)
+		assert.Check(t, cmp.Equal(string(body), "hello world!"))
+
+		if atomic.AddInt32(&failures, -1) >= 0 {
+			rw.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		rw.WriteHeader(http.StatusOK)
+	})
+
+	newRequest := func(t *testing.T) *http.Request {
+		return newHTTPRequestForTesting(t, http.MethodPost, httpServerURL.String(), strings.NewReader("hello world!"))
+	}
+
+	t.Run("succeeds after retries", func(t *testing.T) {
+		atomic.StoreInt32(&failures, 2)
+
+		var backoffs []int
+		spy := &doerSpy{doer: httpServer.Client()}
+		resp, err := DoerWrapRetry(spy, RetryOptions{
+			MaxAttempts: 5,
+			Backoff: func(attempt int) time.Duration {
+				backoffs = append(backoffs, attempt)
+				return time.Millisecond
+			},
+		}).Do(newRequest(t))
+		assert.NilError(t, err)
+		assert.NilError(t, resp.Body.Close())
+		assert.Check(t, resp.StatusCode == http.StatusOK)
+		assert.Check(t, len(spy.calls) == 3)
+		assert.Check(t, cmp.DeepEqual(backoffs, []int{1, 2}))
+	})
+
+	t.Run("max attempts reached", func(t *testing.T) {
+		atomic.StoreInt32(&failures, 5)
+
+		spy := &doerSpy{doer: httpServer.Client()}
+		resp, err := DoerWrapRetry(spy, RetryOptions{MaxAttempts: 3}).Do(newRequest(t))
+		assert.NilError(t, err)
+		assert.NilError(t, resp.Body.Close())
+		assert.Check(t, resp.StatusCode == http.StatusServiceUnavailable)
+		assert.Check(t, len(spy.calls) == 3)
+	})
+
+	t.Run("max elapsed time reached", func(t *testing.T) {
+		atomic.StoreInt32(&failures, 5)
+
+		spy := &doerSpy{doer: httpServer.Client()}
+		resp, err := DoerWrapRetry(spy, RetryOptions{
+			MaxAttempts:    5,
+			Backoff:        NewExponentialBackoff(RetryBackoffConfig{InitialInterval: time.Millisecond}).Backoff,
+			MaxElapsedTime: time.Nanosecond,
+		}).Do(newRequest(t))
+		assert.NilError(t, err)
+		assert.NilError(t, resp.Body.Close())
+		assert.Check(t, resp.StatusCode == http.StatusServiceUnavailable)
+		assert.Check(t, len(spy.calls) == 1)
+	})
+
+	t.Run("not retryable", func(t *testing.T) {
+		atomic.StoreInt32(&failures, 5)
+
+		spy := &doerSpy{doer: httpServer.Client()}
+		resp, err := DoerWrapRetry(spy, RetryOptions{
+			MaxAttempts: 3,
+			Retryable:   func(*http.Response, error) bool { return false },
+		}).Do(newRequest(t))
+		assert.NilError(t, err)
+		assert.NilError(t, resp.Body.Close())
+		assert.Check(t, resp.StatusCode == http.StatusServiceUnavailable)
+		assert.Check(t, len(spy.calls) == 1)
+	})
+
+	t.Run("transport error", func(t *testing.T) {
+		spy := &doerSpy{doer: &doerFail{err: errors.New("connection reset")}}
+		resp, err := DoerWrapRetry(spy, RetryOptions{MaxAttempts: 3}).Do(newRequest(t))
+		assert.ErrorContains(t, err, "connection reset")
+		assert.Check(t, resp == nil)
+		assert.Check(t, len(spy.calls) == 3)
+	})
+
+	t.Run("body can't be rewound", func(t *testing.T) {
+		atomic.StoreInt32(&failures, 5)
+
+		req := newRequest(t)
+		req.GetBody = nil
+
+		spy := &doerSpy{doer: httpServer.Client()}
+		resp, err := DoerWrapRetry(spy, RetryOptions{MaxAttempts: 3}).Do(req)
+		assert.NilError(t, err)
+		assert.NilError(t, resp.Body.Close())
+		assert.Check(t, resp.StatusCode == http.StatusServiceUnavailable)
+		assert.Check(t, len(spy.calls) == 1)
+
+		req = newRequest(t)
+		req.GetBody = nil
+
+		spy = &doerSpy{doer: &doerFail{err: errors.New("connection reset")}}
+		resp, err = DoerWrapRetry(spy, RetryOptions{MaxAttempts: 3}).Do(req)
+		assert.ErrorContains(t, err,
+			"unable to retry request after attempt 1 failed with connection reset: request body can't be rewound: request GetBody is unset",
+		)
+		assert.Check(t, resp == nil)
+		assert.Check(t, len(spy.calls) == 1)
+	})
+
+	t.Run("context done while waiting", func(t *testing.T) {
+		atomic.StoreInt32(&failures, 5)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		spy := &doerSpy{doer: httpServer.Client()}
+		resp, err := DoerWrapRetry(spy, RetryOptions{
+			MaxAttempts: 3,
+			Backoff:     func(int) time.Duration { return time.Hour },
+		}).Do(newRequest(t).WithContext(ctx))
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Check(t, resp == nil)
+		assert.Check(t, len(spy.calls) == 1)
+	})
+}
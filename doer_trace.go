@@ -0,0 +1,40 @@
+package httpclient
+
+import (
+	"context"
+	"net/http"
+)
+
+// DoerWrapTrace wraps the provided doer by calling start before each call, and the finish function it returns after each call.
+// The context returned by start is used as the request context, which allows to plug tracing, like OpenTelemetry spans,
+// without depending on a tracing library. Finish is called with the response and error of the call, once the response headers
+// are received; the response body may still be read afterwards. If start is nil, the doer is called as is.
+func DoerWrapTrace(doer Doer, start func(ctx context.Context, req *http.Request) (context.Context, func(resp *http.Response, err error))) Doer {
+	if start == nil {
+		start = func(ctx context.Context, _ *http.Request) (context.Context, func(*http.Response, error)) {
+			return ctx, func(*http.Response, error) {}
+		}
+	}
+
+	return &doerWrapTrace{
+		doer:  doer,
+		start: start,
+	}
+}
+
+type doerWrapTrace struct {
+	doer  Doer
+	start func(ctx context.Context, req *http.Request) (context.Context, func(resp *http.Response, err error))
+}
+
+func (w doerWrapTrace) Do(req *http.Request) (*http.Response, error) {
+	ctx, finish := w.start(req.Context(), req)
+	if ctx != req.Context() {
+		req = req.WithContext(ctx)
+	}
+
+	resp, err := w.doer.Do(req)
+	finish(resp, err)
+
+	return resp, err
+}
@@ -0,0 +1,66 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/assert/cmp"
+)
+
+func Test_DoerWrapTrace(t *testing.T) {
+	type spanContextKey struct{}
+
+	httpServer, httpServerURL := newHTTPServerForTesting(t, func(rw http.ResponseWriter, r *http.Request) {
+		rw.WriteHeader(http.StatusTeapot)
+	})
+
+	var events []string
+
+	start := func(ctx context.Context, req *http.Request) (context.Context, func(*http.Response, error)) {
+		events = append(events, "start "+req.Method+" "+req.URL.Path)
+		return context.WithValue(ctx, spanContextKey{}, "span"), func(resp *http.Response, err error) {
+			if err != nil {
+				events = append(events, "finish error "+err.Error())
+				return
+			}
+			events = append(events, "finish "+resp.Request.Method+" "+resp.Status)
+		}
+	}
+
+	spanReader := func(doer Doer) Doer {
+		return DoerWrapTrace(doer, func(ctx context.Context, _ *http.Request) (context.Context, func(*http.Response, error)) {
+			events = append(events, "span "+ctx.Value(spanContextKey{}).(string))
+			return ctx, func(*http.Response, error) {}
+		})
+	}
+
+	t.Run("success", func(t *testing.T) {
+		events = nil
+
+		resp, err := DoerWrapTrace(spanReader(httpServer.Client()), start).
+			Do(newHTTPRequestForTesting(t, http.MethodGet, httpServerURL.String()+"/foo", nil))
+		assert.NilError(t, err)
+		assert.NilError(t, resp.Body.Close())
+		assert.Check(t, cmp.DeepEqual(events, []string{"start GET /foo", "span span", "finish GET 418 I'm a teapot"}))
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		events = nil
+
+		resp, err := DoerWrapTrace(spanReader(&doerFail{err: errors.New("boom")}), start).
+			Do(newHTTPRequestForTesting(t, http.MethodPost, httpServerURL.String()+"/bar", nil))
+		assert.Error(t, err, "boom")
+		assert.Check(t, resp == nil)
+		assert.Check(t, cmp.DeepEqual(events, []string{"start POST /bar", "span span", "finish error boom"}))
+	})
+
+	t.Run("nil start", func(t *testing.T) {
+		resp, err := DoerWrapTrace(httpServer.Client(), nil).Do(newHTTPRequestForTesting(t, http.MethodGet, httpServerURL.String(), nil))
+		assert.NilError(t, err)
+		assert.NilError(t, resp.Body.Close())
+		assert.Equal(t, resp.StatusCode, http.StatusTeapot)
+	})
+}
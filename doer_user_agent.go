@@ -0,0 +1,27 @@
+package httpclient
+
+import (
+	"net/http"
+)
+
+// DoerWrapUserAgent wraps the provided doer by setting the User-Agent header to the provided user agent,
+// for requests that do not set it. The provided requests are not modified, a copy being sent instead.
+func DoerWrapUserAgent(doer Doer, userAgent string) Doer {
+	return &doerWrapUserAgent{
+		doer:      doer,
+		userAgent: userAgent,
+	}
+}
+
+type doerWrapUserAgent struct {
+	doer      Doer
+	userAgent string
+}
+
+func (w doerWrapUserAgent) Do(req *http.Request) (*http.Response, error) {
+	if _, exists := req.Header["User-Agent"]; !exists {
+		req = req.Clone(req.Context())
+		req.Header.Set("User-Agent", w.userAgent)
+	}
+	return w.doer.Do(req)
+}
@@ -0,0 +1,59 @@
+package httpclient
+
+import (
+	"io"
+	"net/http"
+	"sync"
+	"testing"
+
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/assert/cmp"
+)
+
+func Test_DoerWrapUserAgent(t *testing.T) {
+	httpServer, httpServerURL := newHTTPServerForTesting(t, func(rw http.ResponseWriter, r *http.Request) {
+		_, _ = rw.Write([]byte(r.UserAgent()))
+	})
+
+	doer := DoerWrapUserAgent(httpServer.Client(), "httpclient/1.0")
+
+	doRequest := func(t *testing.T, req *http.Request) string {
+		resp, err := doer.Do(req)
+		assert.NilError(t, err)
+		defer func() { assert.NilError(t, resp.Body.Close()) }()
+
+		userAgent, err := io.ReadAll(resp.Body)
+		assert.NilError(t, err)
+		return string(userAgent)
+	}
+
+	t.Run("not set", func(t *testing.T) {
+		req := newHTTPRequestForTesting(t, http.MethodGet, httpServerURL.String(), nil)
+		assert.Check(t, cmp.Equal(doRequest(t, req), "httpclient/1.0"))
+		assert.Check(t, cmp.Len(req.Header, 0))
+	})
+
+	t.Run("already set", func(t *testing.T) {
+		req := newHTTPRequestForTesting(t, http.MethodGet, httpServerURL.String(), nil)
+		req.Header.Set("User-Agent", "custom/2.0")
+		assert.Check(t, cmp.Equal(doRequest(t, req), "custom/2.0"))
+	})
+
+	t.Run("concurrent use", func(t *testing.T) {
+		req := newHTTPRequestForTesting(t, http.MethodGet, httpServerURL.String(), nil)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				resp, err := doer.Do(req)
+				assert.Check(t, err)
+				if err == nil {
+					_ = resp.Body.Close()
+				}
+			}()
+		}
+		wg.Wait()
+	})
+}
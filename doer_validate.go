@@ -0,0 +1,56 @@
+package httpclient
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// ErrInvalidRequest is returned by doers wrapped with DoerWrapValidate for malformed requests.
+var ErrInvalidRequest = errors.New("invalid request")
+
+// DoerWrapValidate wraps the provided doer by checking requests are well-formed before calling the doer:
+// the request must have a method, an url with a scheme and a host, and no placeholder left in the url path,
+// like "{userID}" that PathReplacer was supposed to replace. Malformed requests fail with ErrInvalidRequest.
+// It is meant as a safety net for development builds.
+func DoerWrapValidate(doer Doer) Doer {
+	return &doerWrapValidate{doer: doer}
+}
+
+type doerWrapValidate struct {
+	doer Doer
+}
+
+func (w doerWrapValidate) Do(req *http.Request) (*http.Response, error) {
+	if err := validateRequest(req); err != nil {
+		return nil, err
+	}
+	return w.doer.Do(req)
+}
+
+func validateRequest(req *http.Request) error {
+	if req.Method == "" {
+		return fmt.Errorf("%w: method is empty", ErrInvalidRequest)
+	}
+
+	if req.URL == nil {
+		return fmt.Errorf("%w: url is unset", ErrInvalidRequest)
+	}
+
+	if req.URL.Scheme == "" {
+		return fmt.Errorf("%w: url %s has no scheme", ErrInvalidRequest, req.URL)
+	}
+
+	if req.URL.Host == "" {
+		return fmt.Errorf("%w: url %s has no host", ErrInvalidRequest, req.URL)
+	}
+
+	if start := strings.Index(req.URL.Path, "{"); start >= 0 {
+		if end := strings.Index(req.URL.Path[start:], "}"); end >= 0 {
+			return fmt.Errorf("%w: url path %s has unreplaced placeholder %s", ErrInvalidRequest, req.URL.Path, req.URL.Path[start:start+end+1])
+		}
+	}
+
+	return nil
+}
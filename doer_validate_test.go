@@ -0,0 +1,60 @@
+package httpclient
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func Test_DoerWrapValidate(t *testing.T) {
+	httpServer, httpServerURL := newHTTPServerForTesting(t, func(rw http.ResponseWriter, r *http.Request) {
+		rw.WriteHeader(http.StatusOK)
+	})
+
+	doer := DoerWrapValidate(httpServer.Client())
+
+	t.Run("valid request", func(t *testing.T) {
+		resp, err := doer.Do(newHTTPRequestForTesting(t, http.MethodGet, httpServerURL.String()+"/users/42", nil))
+		assert.NilError(t, err)
+		assert.NilError(t, resp.Body.Close())
+		assert.Equal(t, resp.StatusCode, http.StatusOK)
+	})
+
+	t.Run("malformed requests", func(t *testing.T) {
+		for name, test := range map[string]struct {
+			req         *http.Request
+			expectedErr string
+		}{
+			"empty method": {
+				req:         &http.Request{URL: &url.URL{Scheme: "http", Host: "localhost"}},
+				expectedErr: "invalid request: method is empty",
+			},
+			"unset url": {
+				req:         &http.Request{Method: http.MethodGet},
+				expectedErr: "invalid request: url is unset",
+			},
+			"no scheme": {
+				req:         &http.Request{Method: http.MethodGet, URL: &url.URL{Host: "localhost", Path: "/foo"}},
+				expectedErr: "invalid request: url //localhost/foo has no scheme",
+			},
+			"no host": {
+				req:         &http.Request{Method: http.MethodGet, URL: &url.URL{Scheme: "http", Path: "/foo"}},
+				expectedErr: "invalid request: url http:///foo has no host",
+			},
+			"unreplaced placeholder": {
+				req:         &http.Request{Method: http.MethodGet, URL: &url.URL{Scheme: "http", Host: "localhost", Path: "/users/{userID}/email"}},
+				expectedErr: "invalid request: url path /users/{userID}/email has unreplaced placeholder {userID}",
+			},
+		} {
+			test := test
+			t.Run(name, func(t *testing.T) {
+				resp, err := doer.Do(test.req)
+				assert.ErrorIs(t, err, ErrInvalidRequest)
+				assert.Error(t, err, test.expectedErr)
+				assert.Check(t, resp == nil)
+			})
+		}
+	})
+}
@@ -0,0 +1,151 @@
+package httpclient
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// DefaultValuesTag is the struct tag used by RequestBuilder.SetQueryParamsFromStruct and RequestBuilder.SendFormFromStruct.
+const DefaultValuesTag = "url"
+
+// EncodeValues encodes the provided struct, or pointer to struct, to url values.
+// The name of each exported field is read from the provided struct tag, using the field name if the tag is unset.
+// The tag value can be followed by ",omitempty" to skip zero values, and "-" skips the field.
+// Embedded structs without tag are flattened. Slices and arrays are encoded as multiple values,
+// nil pointers are skipped, and encoding.TextMarshaler implementations are used when available.
+func EncodeValues(v any, tag string) (url.Values, error) {
+	values := make(url.Values)
+
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return values, nil
+		}
+		rv = rv.Elem()
+	}
+
+	if rv.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("unable to encode %T: expected a struct", v)
+	}
+
+	if err := encodeStructValues(values, rv, tag); err != nil {
+		return nil, err
+	}
+
+	return values, nil
+}
+
+func encodeStructValues(values url.Values, rv reflect.Value, tag string) error {
+	rt := rv.Type()
+
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		if !field.IsExported() && !field.Anonymous {
+			continue
+		}
+
+		name, opts, _ := strings.Cut(field.Tag.Get(tag), ",")
+		if name == "-" {
+			continue
+		}
+
+		fv := rv.Field(i)
+		if opts == "omitempty" && fv.IsZero() {
+			continue
+		}
+
+		if field.Anonymous && name == "" {
+			embedded := fv
+			for embedded.Kind() == reflect.Pointer {
+				if embedded.IsNil() {
+					break
+				}
+				embedded = embedded.Elem()
+			}
+
+			if embedded.Kind() == reflect.Struct {
+				if err := encodeStructValues(values, embedded, tag); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+
+		if !field.IsExported() {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		if err := encodeFieldValues(values, name, fv); err != nil {
+			return fmt.Errorf("unable to encode field %s: %w", field.Name, err)
+		}
+	}
+
+	return nil
+}
+
+func encodeFieldValues(values url.Values, name string, fv reflect.Value) error {
+	if fv.Kind() == reflect.Pointer {
+		if fv.IsNil() {
+			return nil
+		}
+		if _, isMarshaler := fv.Interface().(encoding.TextMarshaler); !isMarshaler {
+			fv = fv.Elem()
+		}
+	}
+
+	if fv.Kind() == reflect.Slice || fv.Kind() == reflect.Array {
+		if _, isMarshaler := fv.Interface().(encoding.TextMarshaler); !isMarshaler && fv.Type().Elem().Kind() != reflect.Uint8 {
+			for i := 0; i < fv.Len(); i++ {
+				if err := encodeFieldValues(values, name, fv.Index(i)); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+	}
+
+	value, err := encodeValue(fv)
+	if err != nil {
+		return err
+	}
+
+	values.Add(name, value)
+
+	return nil
+}
+
+func encodeValue(fv reflect.Value) (string, error) {
+	if marshaler, ok := fv.Interface().(encoding.TextMarshaler); ok {
+		raw, err := marshaler.MarshalText()
+		if err != nil {
+			return "", fmt.Errorf("unable to marshal text: %w", err)
+		}
+		return string(raw), nil
+	}
+
+	switch fv.Kind() {
+	case reflect.String:
+		return fv.String(), nil
+	case reflect.Bool:
+		return strconv.FormatBool(fv.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(fv.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(fv.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(fv.Float(), 'f', -1, fv.Type().Bits()), nil
+	case reflect.Slice: // only []byte reach this point
+		return string(fv.Bytes()), nil
+	default:
+		return "", errors.New("unsupported type " + fv.Type().String())
+	}
+}
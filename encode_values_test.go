@@ -0,0 +1,98 @@
+package httpclient
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+)
+
+type encodeValuesFailingMarshaler struct{}
+
+func (encodeValuesFailingMarshaler) MarshalText() ([]byte, error) { return nil, errors.New("boom") }
+
+func Test_EncodeValues(t *testing.T) {
+	t.Run("ok", func(t *testing.T) {
+		type Pagination struct {
+			Page  int `url:"page"`
+			Limit int `url:"limit,omitempty"`
+		}
+
+		type Filters struct {
+			*Pagination
+			Tags []string `url:"tag"`
+		}
+
+		var (
+			name  = "john"
+			empty *string
+		)
+
+		values, err := EncodeValues(&struct {
+			Filters
+			Name      *string   `url:"name"`
+			Nickname  *string   `url:"nickname"`
+			Admin     bool      `url:"admin"`
+			Ratio     float64   `url:"ratio"`
+			Since     time.Time `url:"since"`
+			Untagged  uint8
+			Ignored   string `url:"-"`
+			Omitted   string `url:"omitted,omitempty"`
+			Raw       []byte `url:"raw"`
+			unexposed string
+		}{
+			Filters: Filters{
+				Pagination: &Pagination{Page: 2},
+				Tags:       []string{"foo", "bar"},
+			},
+			Name:      &name,
+			Nickname:  empty,
+			Admin:     true,
+			Ratio:     0.5,
+			Since:     time.Date(2023, time.January, 2, 3, 4, 5, 0, time.UTC),
+			Untagged:  42,
+			Ignored:   "ignored",
+			Raw:       []byte("raw"),
+			unexposed: "unexposed",
+		}, DefaultValuesTag)
+		assert.NilError(t, err)
+		assert.DeepEqual(t, values, url.Values{
+			"page":     {"2"},
+			"tag":      {"foo", "bar"},
+			"name":     {"john"},
+			"admin":    {"true"},
+			"ratio":    {"0.5"},
+			"since":    {"2023-01-02T03:04:05Z"},
+			"Untagged": {"42"},
+			"raw":      {"raw"},
+		})
+	})
+
+	t.Run("custom tag", func(t *testing.T) {
+		values, err := EncodeValues(struct {
+			Foo string `form:"foo" url:"bar"`
+		}{Foo: "foo"}, "form")
+		assert.NilError(t, err)
+		assert.DeepEqual(t, values, url.Values{"foo": {"foo"}})
+	})
+
+	t.Run("nil pointer", func(t *testing.T) {
+		var v *struct{ Foo string }
+		values, err := EncodeValues(v, DefaultValuesTag)
+		assert.NilError(t, err)
+		assert.DeepEqual(t, values, url.Values{})
+	})
+
+	t.Run("ko", func(t *testing.T) {
+		_, err := EncodeValues(map[string]string{}, DefaultValuesTag)
+		assert.Error(t, err, "unable to encode map[string]string: expected a struct")
+
+		_, err = EncodeValues(struct{ Foo map[string]string }{}, DefaultValuesTag)
+		assert.Error(t, err, "unable to encode field Foo: unsupported type map[string]string")
+
+		_, err = EncodeValues(struct{ Foo encodeValuesFailingMarshaler }{}, DefaultValuesTag)
+		assert.Error(t, err, "unable to encode field Foo: unable to marshal text: boom")
+	})
+}
@@ -6,5 +6,6 @@ require (
 	github.com/google/go-cmp v0.5.9
 	go.uber.org/multierr v1.11.0
 	golang.org/x/exp v0.0.0-20230116083435-1de6713980de
+	golang.org/x/text v0.6.0
 	gotest.tools/v3 v3.5.0
 )
@@ -1,10 +1,14 @@
 package httpclient
 
 import (
+	"bytes"
+	"crypto/tls"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
+
+	"golang.org/x/exp/slices"
 )
 
 // ParsePostForm sets req.PostForm by calling req.ParseForm forms, but also handles non-standard http methods.
@@ -36,3 +40,119 @@ func ParsePostForm(req *http.Request) error {
 
 	return nil
 }
+
+// Values creates url values from the provided alternating keys and values.
+// Example: Values("foo", "bar", "foo", "baz") is equivalent to url.Values{"foo": {"bar", "baz"}}.
+// It panics if the number of provided arguments is odd.
+func Values(kv ...string) url.Values {
+	if len(kv)%2 == 1 {
+		panic("httpclient.Values: odd argument count")
+	}
+
+	values := make(url.Values, len(kv)/2)
+	for i := 0; i < len(kv); i += 2 {
+		values.Add(kv[i], kv[i+1])
+	}
+
+	return values
+}
+
+// setRequestSizedBody sets the request content length to the provided size, and, if unset,
+// req.GetBody to seek the provided body back to its current position, if it implements io.Seeker.
+func setRequestSizedBody(req *http.Request, body io.Reader, size int64) error {
+	req.ContentLength = size
+	if size == 0 {
+		req.Body = http.NoBody
+		req.GetBody = func() (io.ReadCloser, error) { return http.NoBody, nil }
+		return nil
+	}
+
+	seeker, isSeeker := body.(io.Seeker)
+	if !isSeeker || req.GetBody != nil {
+		return nil
+	}
+
+	offset, err := seeker.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return fmt.Errorf("unable to get body position: %v", err)
+	}
+
+	req.GetBody = func() (io.ReadCloser, error) {
+		if _, err := seeker.Seek(offset, io.SeekStart); err != nil {
+			return nil, fmt.Errorf("unable to seek body: %v", err)
+		}
+		return io.NopCloser(body), nil
+	}
+
+	return nil
+}
+
+// setRequestGetBody sets req.GetBody, if unset, by buffering the request body in memory.
+func setRequestGetBody(req *http.Request) error {
+	if req.Body == nil || req.Body == http.NoBody || req.GetBody != nil {
+		return nil
+	}
+
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		return fmt.Errorf("unable to read body: %v", err)
+	}
+	_ = req.Body.Close()
+
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(body)), nil
+	}
+
+	return nil
+}
+
+// progressReadCloser calls progress with the amount of bytes read so far, each time bytes are read on the underlying ReadCloser.
+type progressReadCloser struct {
+	io.ReadCloser
+	read     int64
+	total    int64
+	progress func(read, total int64)
+}
+
+func (r *progressReadCloser) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	if n > 0 {
+		r.read += int64(n)
+		r.progress(r.read, r.total)
+	}
+	return n, err
+}
+
+// clientWithHTTP1Only returns a copy of the provided client whose transport can't negotiate HTTP/2.
+func clientWithHTTP1Only(doer Doer) (Doer, error) {
+	client, isHTTPClient := doer.(*http.Client)
+	if !isHTTPClient {
+		return nil, fmt.Errorf("unable to force HTTP/1.1: client %T is not an *http.Client", doer)
+	}
+
+	roundTripper := client.Transport
+	if roundTripper == nil {
+		roundTripper = http.DefaultTransport
+	}
+
+	transport, isHTTPTransport := roundTripper.(*http.Transport)
+	if !isHTTPTransport {
+		return nil, fmt.Errorf("unable to force HTTP/1.1: client transport %T is not an *http.Transport", roundTripper)
+	}
+
+	transport = transport.Clone()
+	transport.ForceAttemptHTTP2 = false
+	transport.TLSNextProto = make(map[string]func(string, *tls.Conn) http.RoundTripper)
+	transport.DisableKeepAlives = true
+	if transport.TLSClientConfig != nil {
+		if i := slices.Index(transport.TLSClientConfig.NextProtos, "h2"); i >= 0 {
+			transport.TLSClientConfig.NextProtos = slices.Delete(slices.Clone(transport.TLSClientConfig.NextProtos), i, i+1)
+		}
+	}
+
+	clientHTTP1 := *client
+	clientHTTP1.Transport = transport
+
+	return &clientHTTP1, nil
+}
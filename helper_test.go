@@ -74,6 +74,23 @@ func Test_ParsePostForm(t *testing.T) {
 	})
 }
 
+func Test_Values(t *testing.T) {
+	t.Run("even argument count", func(t *testing.T) {
+		assert.DeepEqual(t, Values(), url.Values{})
+		assert.DeepEqual(t, Values("foo", "bar", "hello", "world", "foo", "baz"), url.Values{
+			"foo":   {"bar", "baz"},
+			"hello": {"world"},
+		})
+	})
+
+	t.Run("odd argument count", func(t *testing.T) {
+		defer func() {
+			assert.Check(t, cmp.Equal(recover(), "httpclient.Values: odd argument count"))
+		}()
+		Values("foo", "bar", "hello")
+	})
+}
+
 func newHTTPServerForTesting(t *testing.T, handler http.HandlerFunc) (*httptest.Server, url.URL) {
 	httpServer := httptest.NewServer(handler)
 	t.Cleanup(httpServer.Close)
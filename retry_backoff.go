@@ -0,0 +1,112 @@
+package httpclient
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// RetryBackoffConfig defines the delays computed by ExponentialBackoff.
+type RetryBackoffConfig struct {
+	// InitialInterval is the delay before the first retry.
+	InitialInterval time.Duration
+	// Multiplier is the factor applied to the delay after each attempt. If lower or equal to 1, 2 is used.
+	Multiplier float64
+	// MaxInterval caps the computed delay, before jitter is applied. Zero means no cap.
+	MaxInterval time.Duration
+	// Jitter enables full jitter: the delay is picked randomly between 0 and the computed delay,
+	// which avoids clients retrying all at once.
+	Jitter bool
+	// MaxElapsedTime is the total time budget after which no retry is made, whatever the number of attempts.
+	// Zero means no budget.
+	MaxElapsedTime time.Duration
+	// Rand is used to compute the jitter. Providing a seeded source makes delays deterministic.
+	// If unset, a source seeded with the current time is used.
+	Rand *rand.Rand
+	// Clock is used by ExponentialBackoff.Wait to measure the elapsed time and wait. If unset, RealClock is used.
+	Clock Clock
+}
+
+// ExponentialBackoff computes exponentially growing delays between retry attempts.
+type ExponentialBackoff struct {
+	cfg RetryBackoffConfig
+	m   sync.Mutex // protects cfg.Rand which is not safe for concurrent use
+}
+
+// NewExponentialBackoff creates an exponential backoff, as configured.
+func NewExponentialBackoff(cfg RetryBackoffConfig) *ExponentialBackoff {
+	if cfg.Multiplier <= 1 {
+		cfg.Multiplier = 2
+	}
+
+	if cfg.Rand == nil {
+		cfg.Rand = rand.New(rand.NewSource(time.Now().UnixNano())) //nolint:gosec // no need for a cryptographically secure source
+	}
+
+	if cfg.Clock == nil {
+		cfg.Clock = RealClock()
+	}
+
+	return &ExponentialBackoff{cfg: cfg}
+}
+
+// Backoff returns the delay to wait after the provided attempt, starting at 1, before retrying.
+func (b *ExponentialBackoff) Backoff(attempt int) time.Duration {
+	if attempt < 1 {
+		attempt = 1
+	}
+
+	if b.cfg.InitialInterval <= 0 {
+		return 0
+	}
+
+	delay := time.Duration(math.MaxInt64)
+	// float64(math.MaxInt64) is rounded up to 2^63, which does not fit in an int64: greater values are kept to the int64 maximum
+	if d := float64(b.cfg.InitialInterval) * math.Pow(b.cfg.Multiplier, float64(attempt-1)); d < math.MaxInt64 {
+		delay = time.Duration(d)
+	}
+	if b.cfg.MaxInterval > 0 && delay > b.cfg.MaxInterval {
+		delay = b.cfg.MaxInterval
+	}
+
+	if !b.cfg.Jitter {
+		return delay
+	}
+
+	b.m.Lock()
+	defer b.m.Unlock()
+
+	if delay == math.MaxInt64 {
+		return time.Duration(b.cfg.Rand.Int63())
+	}
+	return time.Duration(b.cfg.Rand.Int63n(int64(delay) + 1))
+}
+
+// Next returns the delay to wait after the provided attempt, starting at 1, before retrying.
+// Elapsed is the time spent since the first attempt started; the returned boolean is false
+// if waiting for the delay would exceed the configured MaxElapsedTime, in which case no retry should be made.
+func (b *ExponentialBackoff) Next(attempt int, elapsed time.Duration) (time.Duration, bool) {
+	delay := b.Backoff(attempt)
+	if b.cfg.MaxElapsedTime > 0 && elapsed+delay > b.cfg.MaxElapsedTime {
+		return 0, false
+	}
+	return delay, true
+}
+
+// Wait waits for the delay returned by Next, the elapsed time being measured since the provided start.
+// It returns false without waiting if no retry should be made, and the context error if the context is done while waiting.
+func (b *ExponentialBackoff) Wait(ctx context.Context, attempt int, start time.Time) (bool, error) {
+	delay, retry := b.Next(attempt, b.cfg.Clock.Now().Sub(start))
+	if !retry {
+		return false, nil
+	}
+
+	select {
+	case <-b.cfg.Clock.After(delay):
+		return true, nil
+	case <-ctx.Done():
+		return false, ctx.Err()
+	}
+}
@@ -0,0 +1,132 @@
+package httpclient
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+)
+
+func Test_ExponentialBackoff_Backoff(t *testing.T) {
+	t.Run("without jitter", func(t *testing.T) {
+		backoff := NewExponentialBackoff(RetryBackoffConfig{
+			InitialInterval: 100 * time.Millisecond,
+			MaxInterval:     time.Second,
+		})
+
+		for attempt, expected := range []time.Duration{
+			100 * time.Millisecond, // attempt 0 is considered as the first attempt
+			100 * time.Millisecond,
+			200 * time.Millisecond,
+			400 * time.Millisecond,
+			800 * time.Millisecond,
+			time.Second,
+			time.Second,
+		} {
+			assert.Equal(t, backoff.Backoff(attempt), expected, "attempt %d", attempt)
+		}
+	})
+
+	t.Run("with jitter", func(t *testing.T) {
+		backoff := NewExponentialBackoff(RetryBackoffConfig{
+			InitialInterval: 100 * time.Millisecond,
+			Multiplier:      3,
+			MaxInterval:     2 * time.Second,
+			Jitter:          true,
+			Rand:            rand.New(rand.NewSource(42)), //nolint:gosec // test
+		})
+
+		var distinct bool
+		for i := 0; i < 100; i++ {
+			for attempt, maxDelay := range map[int]time.Duration{
+				1: 100 * time.Millisecond,
+				2: 300 * time.Millisecond,
+				3: 900 * time.Millisecond,
+				4: 2 * time.Second,
+				5: 2 * time.Second,
+			} {
+				delay := backoff.Backoff(attempt)
+				assert.Check(t, delay >= 0 && delay <= maxDelay, "attempt %d: delay %s not within [0, %s]", attempt, delay, maxDelay)
+				distinct = distinct || delay != maxDelay
+			}
+		}
+		assert.Check(t, distinct, "jitter is expected to change delays")
+	})
+
+	t.Run("large attempts", func(t *testing.T) {
+		for name, cfg := range map[string]RetryBackoffConfig{
+			"without jitter":       {InitialInterval: time.Second},
+			"with jitter":          {InitialInterval: time.Second, Jitter: true},
+			"with max interval":    {InitialInterval: time.Second, MaxInterval: time.Minute},
+			"without interval":     {Jitter: true},
+			"with huge multiplier": {InitialInterval: time.Second, Multiplier: math.MaxFloat64, Jitter: true},
+		} {
+			backoff := NewExponentialBackoff(cfg)
+			for _, attempt := range []int{63, 64, 100, math.MaxInt} {
+				delay := backoff.Backoff(attempt)
+				assert.Check(t, delay >= 0, "%s: attempt %d: negative delay %s", name, attempt, delay)
+				if cfg.MaxInterval > 0 {
+					assert.Check(t, delay <= cfg.MaxInterval, "%s: attempt %d: delay %s above max interval", name, attempt, delay)
+				}
+			}
+		}
+
+		assert.Equal(t, NewExponentialBackoff(RetryBackoffConfig{InitialInterval: time.Second}).Backoff(100), time.Duration(math.MaxInt64))
+	})
+
+	t.Run("with jitter is deterministic with a seeded source", func(t *testing.T) {
+		newBackoff := func() *ExponentialBackoff {
+			return NewExponentialBackoff(RetryBackoffConfig{
+				InitialInterval: time.Second,
+				Jitter:          true,
+				Rand:            rand.New(rand.NewSource(42)), //nolint:gosec // test
+			})
+		}
+
+		a, b := newBackoff(), newBackoff()
+		for attempt := 1; attempt < 10; attempt++ {
+			assert.Equal(t, a.Backoff(attempt), b.Backoff(attempt))
+		}
+	})
+}
+
+func Test_ExponentialBackoff_Next(t *testing.T) {
+	t.Run("without budget", func(t *testing.T) {
+		backoff := NewExponentialBackoff(RetryBackoffConfig{InitialInterval: time.Second})
+
+		delay, retry := backoff.Next(10, 24*time.Hour)
+		assert.Check(t, retry)
+		assert.Equal(t, delay, 512*time.Second)
+	})
+
+	t.Run("retries stop at the elapsed budget", func(t *testing.T) {
+		backoff := NewExponentialBackoff(RetryBackoffConfig{
+			InitialInterval: 100 * time.Millisecond,
+			MaxElapsedTime:  time.Second,
+		})
+
+		var (
+			elapsed time.Duration
+			retries int
+		)
+
+		for attempt := 1; attempt < 100; attempt++ {
+			elapsed += 10 * time.Millisecond // time spent by the attempt itself
+
+			delay, retry := backoff.Next(attempt, elapsed)
+			if !retry {
+				assert.Equal(t, delay, time.Duration(0))
+				break
+			}
+
+			retries++
+			elapsed += delay
+		}
+
+		// 10ms + 100ms + 10ms + 200ms + 10ms + 400ms + 10ms = 740ms, waiting 800ms more would exceed the budget
+		assert.Equal(t, retries, 3)
+		assert.Check(t, elapsed <= time.Second)
+	})
+}
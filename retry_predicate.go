@@ -0,0 +1,78 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+
+	"golang.org/x/exp/slices"
+)
+
+// RetryPredicate returns whether a request should be retried, given the response and error of the attempt.
+// It is meant to be used as RetryOptions.Retryable.
+type RetryPredicate func(resp *http.Response, err error) bool
+
+// RetryOnStatus retries requests whose response status is one of the provided statuses.
+func RetryOnStatus(codes ...int) RetryPredicate {
+	return func(resp *http.Response, err error) bool {
+		return err == nil && resp != nil && slices.Contains(codes, resp.StatusCode)
+	}
+}
+
+// RetryOnServerErrors retries requests whose response status is a 5xx status.
+func RetryOnServerErrors() RetryPredicate {
+	return func(resp *http.Response, err error) bool {
+		return err == nil && resp != nil && resp.StatusCode >= http.StatusInternalServerError && resp.StatusCode <= 599
+	}
+}
+
+// RetryOnNetworkError retries requests that failed with a network error, like connection resets, timeouts,
+// or connections closed by the server.
+// Errors caused by the request context being canceled, or its deadline exceeded, are not retried.
+func RetryOnNetworkError() RetryPredicate {
+	return func(_ *http.Response, err error) bool {
+		if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			return false
+		}
+
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return true
+		}
+
+		// url.Error implements net.Error whatever the error it wraps
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+
+		var netErr net.Error
+		return errors.As(err, &netErr)
+	}
+}
+
+// RetryAny retries requests for which any of the provided predicates returns true.
+func RetryAny(preds ...RetryPredicate) RetryPredicate {
+	return func(resp *http.Response, err error) bool {
+		for _, pred := range preds {
+			if pred(resp, err) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// RetryAll retries requests for which all the provided predicates return true. It never retries if no predicate is provided.
+func RetryAll(preds ...RetryPredicate) RetryPredicate {
+	return func(resp *http.Response, err error) bool {
+		for _, pred := range preds {
+			if !pred(resp, err) {
+				return false
+			}
+		}
+		return len(preds) > 0
+	}
+}
@@ -0,0 +1,59 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"syscall"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func Test_RetryPredicates(t *testing.T) {
+	respWithStatus := func(status int) *http.Response { return &http.Response{StatusCode: status} }
+
+	connReset := &url.Error{Op: "Post", URL: "http://localhost", Err: &net.OpError{Op: "read", Net: "tcp", Err: syscall.ECONNRESET}}
+	alwaysTrue := func(*http.Response, error) bool { return true }
+	alwaysFalse := func(*http.Response, error) bool { return false }
+
+	for name, test := range map[string]struct {
+		pred     RetryPredicate
+		resp     *http.Response
+		err      error
+		expected bool
+	}{
+		"on status, matching":             {pred: RetryOnStatus(http.StatusTooManyRequests, http.StatusBadGateway), resp: respWithStatus(http.StatusBadGateway), expected: true},
+		"on status, not matching":         {pred: RetryOnStatus(http.StatusTooManyRequests), resp: respWithStatus(http.StatusBadGateway), expected: false},
+		"on status, nil response":         {pred: RetryOnStatus(http.StatusTooManyRequests), err: connReset, expected: false},
+		"on server errors, 5xx":           {pred: RetryOnServerErrors(), resp: respWithStatus(http.StatusServiceUnavailable), expected: true},
+		"on server errors, 4xx":           {pred: RetryOnServerErrors(), resp: respWithStatus(http.StatusNotFound), expected: false},
+		"on server errors, nil response":  {pred: RetryOnServerErrors(), err: connReset, expected: false},
+		"on network error, conn reset":    {pred: RetryOnNetworkError(), err: connReset, expected: true},
+		"on network error, EOF":           {pred: RetryOnNetworkError(), err: &url.Error{Op: "Get", URL: "http://localhost", Err: io.EOF}, expected: true},
+		"on network error, no error":      {pred: RetryOnNetworkError(), resp: respWithStatus(http.StatusServiceUnavailable), expected: false},
+		"on network error, context error": {pred: RetryOnNetworkError(), err: &url.Error{Op: "Get", URL: "http://localhost", Err: context.Canceled}, expected: false},
+		"on network error, other error":   {pred: RetryOnNetworkError(), err: &url.Error{Op: "Get", URL: "foo://localhost", Err: errors.New("unsupported protocol scheme")}, expected: false},
+		"on network error, wrapped":       {pred: RetryOnNetworkError(), err: fmt.Errorf("unable to execute request: %w", connReset), expected: true},
+		"any, one true":                   {pred: RetryAny(alwaysFalse, alwaysTrue), expected: true},
+		"any, all false":                  {pred: RetryAny(alwaysFalse, alwaysFalse), expected: false},
+		"any, none":                       {pred: RetryAny(), expected: false},
+		"all, all true":                   {pred: RetryAll(alwaysTrue, alwaysTrue), expected: true},
+		"all, one false":                  {pred: RetryAll(alwaysTrue, alwaysFalse), expected: false},
+		"all, none":                       {pred: RetryAll(), expected: false},
+		"composed": {
+			pred:     RetryAny(RetryOnNetworkError(), RetryAll(RetryOnServerErrors(), RetryOnStatus(http.StatusBadGateway))),
+			resp:     respWithStatus(http.StatusBadGateway),
+			expected: true,
+		},
+	} {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.pred(test.resp, test.err), test.expected)
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package httpclienttest
+
+import (
+	"sync"
+	"time"
+)
+
+// FakeClock implements httpclient.Clock, its time only changes when Advance is called.
+// It is safe to call it concurrently.
+type FakeClock struct {
+	m       sync.Mutex
+	cond    *sync.Cond
+	now     time.Time
+	waiters []fakeClockWaiter
+}
+
+type fakeClockWaiter struct {
+	deadline time.Time
+	c        chan time.Time
+}
+
+// NewFakeClock creates a fake clock whose current time is the provided time.
+func NewFakeClock(now time.Time) *FakeClock {
+	clock := &FakeClock{now: now}
+	clock.cond = sync.NewCond(&clock.m)
+	return clock
+}
+
+// Now returns the current time of the clock.
+func (c *FakeClock) Now() time.Time {
+	c.m.Lock()
+	defer c.m.Unlock()
+	return c.now
+}
+
+// After returns a channel on which the current time is sent once the clock is advanced by at least the provided duration.
+func (c *FakeClock) After(d time.Duration) <-chan time.Time {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	ch := make(chan time.Time, 1)
+	if d <= 0 {
+		ch <- c.now
+		return ch
+	}
+
+	c.waiters = append(c.waiters, fakeClockWaiter{deadline: c.now.Add(d), c: ch})
+	c.cond.Broadcast()
+
+	return ch
+}
+
+// Advance moves the current time of the clock forward, and fires the channels returned by After whose deadline is reached.
+func (c *FakeClock) Advance(d time.Duration) {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	c.now = c.now.Add(d)
+
+	waiters := c.waiters[:0]
+	for _, waiter := range c.waiters {
+		if waiter.deadline.After(c.now) {
+			waiters = append(waiters, waiter)
+			continue
+		}
+		waiter.c <- c.now
+	}
+	c.waiters = waiters
+}
+
+// BlockUntilWaiters blocks until at least n channels returned by After are waiting for the clock to be advanced.
+// It is useful to advance the clock only once the tested code waits.
+func (c *FakeClock) BlockUntilWaiters(n int) {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	for len(c.waiters) < n {
+		c.cond.Wait()
+	}
+}
@@ -0,0 +1,119 @@
+package httpclienttest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"golang.org/x/exp/slog"
+	"gotest.tools/v3/assert"
+
+	"github.com/krostar/httpclient"
+)
+
+func Test_FakeClock(t *testing.T) {
+	start := time.Date(2023, time.January, 1, 0, 0, 0, 0, time.UTC)
+	clock := NewFakeClock(start)
+
+	assert.Equal(t, clock.Now(), start)
+	assert.Equal(t, <-clock.After(0), start)
+
+	after := clock.After(time.Second)
+
+	clock.Advance(500 * time.Millisecond)
+	select {
+	case <-after:
+		t.Fatal("channel fired before its deadline")
+	default:
+	}
+
+	clock.Advance(500 * time.Millisecond)
+	assert.Equal(t, <-after, start.Add(time.Second))
+	assert.Equal(t, clock.Now(), start.Add(time.Second))
+}
+
+func Test_FakeClock_retryBackoff(t *testing.T) {
+	start := time.Date(2023, time.January, 1, 0, 0, 0, 0, time.UTC)
+	clock := NewFakeClock(start)
+
+	backoff := httpclient.NewExponentialBackoff(httpclient.RetryBackoffConfig{
+		InitialInterval: time.Second,
+		MaxElapsedTime:  10 * time.Second,
+		Clock:           clock,
+	})
+
+	type waitResult struct {
+		retry bool
+		err   error
+	}
+
+	results := make(chan waitResult)
+	go func() {
+		defer close(results)
+		for attempt := 1; ; attempt++ {
+			retry, err := backoff.Wait(context.Background(), attempt, start)
+			results <- waitResult{retry: retry, err: err}
+			if !retry {
+				return
+			}
+		}
+	}()
+
+	// waits 1s, 2s, and 4s, then waiting 8s more would exceed the 10s budget
+	for _, delay := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second} {
+		clock.BlockUntilWaiters(1)
+		clock.Advance(delay)
+		assert.Equal(t, <-results, waitResult{retry: true})
+	}
+
+	assert.Equal(t, <-results, waitResult{retry: false})
+	assert.Equal(t, clock.Now(), start.Add(7*time.Second))
+}
+
+func Test_FakeClock_faultInjectionLatency(t *testing.T) {
+	clock := NewFakeClock(time.Now())
+
+	doer := httpclient.DoerWrapFaultInjection(NewDoerStub([]DoerStubCall{
+		{Response: &http.Response{StatusCode: http.StatusOK}},
+	}, false), httpclient.FaultConfig{Latency: time.Minute, Clock: clock})
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, "/", nil)
+	assert.NilError(t, err)
+
+	cresp := make(chan *http.Response)
+	go func() {
+		resp, err := doer.Do(req)
+		assert.Check(t, err == nil)
+		cresp <- resp
+	}()
+
+	clock.BlockUntilWaiters(1)
+	clock.Advance(time.Minute)
+	assert.Equal(t, (<-cresp).StatusCode, http.StatusOK)
+}
+
+func Test_FakeClock_logging(t *testing.T) {
+	clock := NewFakeClock(time.Now())
+
+	doer := DoerFromHandler(http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+		clock.Advance(21 * time.Millisecond)
+		rw.WriteHeader(http.StatusOK)
+	}))
+
+	var output bytes.Buffer
+	logger := slog.New(slog.HandlerOptions{}.NewJSONHandler(&output))
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, "/", nil)
+	assert.NilError(t, err)
+
+	resp, err := httpclient.DoerWrapLoggingWithClock(doer, logger, clock).Do(req)
+	assert.NilError(t, err)
+	assert.NilError(t, resp.Body.Close())
+
+	var log struct{ Elapsed time.Duration }
+	assert.NilError(t, json.Unmarshal(output.Bytes(), &log))
+	assert.Equal(t, log.Elapsed, 21*time.Millisecond)
+}
@@ -0,0 +1,37 @@
+package httpclienttest
+
+import (
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/krostar/httpclient"
+)
+
+// DoerFromHandler returns a Doer that calls the provided handler in memory for each request, without any network involved.
+// The handler output is recorded and returned as the response.
+func DoerFromHandler(h http.Handler) httpclient.Doer {
+	return doerHandler{handler: h}
+}
+
+type doerHandler struct {
+	handler http.Handler
+}
+
+func (d doerHandler) Do(req *http.Request) (*http.Response, error) {
+	serverReq := req.Clone(req.Context())
+	if serverReq.Body == nil {
+		serverReq.Body = http.NoBody
+	}
+	serverReq.RequestURI = req.URL.RequestURI()
+
+	rec := httptest.NewRecorder()
+	d.handler.ServeHTTP(rec, serverReq)
+
+	resp := rec.Result()
+	resp.Request = req
+	if resp.ContentLength < 0 { // the whole body is known
+		resp.ContentLength = int64(rec.Body.Len())
+	}
+
+	return resp, nil
+}
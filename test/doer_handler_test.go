@@ -0,0 +1,54 @@
+package httpclienttest
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/krostar/httpclient"
+)
+
+func Test_DoerFromHandler(t *testing.T) {
+	doer := DoerFromHandler(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		assert.NilError(t, err)
+
+		rw.Header().Set("Method", r.Method)
+		rw.WriteHeader(http.StatusTeapot)
+		_, err = rw.Write(append([]byte(r.URL.RequestURI()+" "), body...))
+		assert.NilError(t, err)
+	}))
+
+	t.Run("with body", func(t *testing.T) {
+		var body string
+
+		assert.NilError(t, httpclient.NewRequest(http.MethodPost, "http://example.com/foo?bar=baz").
+			Client(doer).
+			SendJSON("hello").
+			Do(context.Background()).
+			OnStatus(http.StatusTeapot, func(resp *http.Response) error {
+				assert.Check(t, resp.Header.Get("Method") == http.MethodPost)
+				raw, err := io.ReadAll(resp.Body)
+				body = string(raw)
+				return err
+			}).
+			Error(),
+		)
+		assert.Equal(t, body, `/foo?bar=baz "hello"`)
+	})
+
+	t.Run("without body", func(t *testing.T) {
+		req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, "http://example.com/", nil)
+		assert.NilError(t, err)
+
+		resp, err := doer.Do(req)
+		assert.NilError(t, err)
+		defer func() { _ = resp.Body.Close() }()
+
+		assert.Check(t, resp.StatusCode == http.StatusTeapot)
+		assert.Check(t, resp.Request == req)
+	})
+}
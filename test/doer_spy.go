@@ -1,9 +1,12 @@
 package httpclienttest
 
 import (
+	"fmt"
 	"net/http"
 	"sync"
 
+	"go.uber.org/multierr"
+
 	"github.com/krostar/httpclient"
 )
 
@@ -47,6 +50,22 @@ func (d *DoerSpy) Calls() []DoerSpyRecord {
 	return calls
 }
 
+// AssertNoneMatch returns an error if any of the calls made on the Doer matches the provided matcher.
+// Unlike Calls, the list of calls is not cleared.
+func (d *DoerSpy) AssertNoneMatch(m RequestMatcher) error {
+	d.m.Lock()
+	defer d.m.Unlock()
+
+	var errs []error
+	for i, call := range d.calls {
+		if err := m.MatchRequest(call.InputRequest); err == nil {
+			errs = append(errs, fmt.Errorf("call #%d %s %s matches", i, call.InputRequest.Method, call.InputRequest.URL.String()))
+		}
+	}
+
+	return multierr.Combine(errs...)
+}
+
 // DoerSpyRecord stores input and outputs of one Doer call.
 type DoerSpyRecord struct {
 	InputRequest   *http.Request
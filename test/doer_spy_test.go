@@ -56,3 +56,23 @@ func Test_DoerSpy(t *testing.T) {
 	))
 	assert.Check(t, len(spiedClient.Calls()) == 0)
 }
+
+func Test_DoerSpy_AssertNoneMatch(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.WriteHeader(http.StatusTeapot)
+	}))
+	defer srv.Close()
+
+	spiedClient := NewDoerSpy(srv.Client())
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, srv.URL+"/foo", nil)
+	assert.NilError(t, err)
+
+	resp, err := spiedClient.Do(req)
+	assert.NilError(t, err)
+	assert.NilError(t, resp.Body.Close())
+
+	assert.NilError(t, spiedClient.AssertNoneMatch(NewRequestMatcherBuilder().Method(http.MethodPost)))
+	assert.Error(t, spiedClient.AssertNoneMatch(NewRequestMatcherBuilder().Method(http.MethodGet)), "call #0 GET "+srv.URL+"/foo matches")
+	assert.Check(t, len(spiedClient.Calls()) == 1)
+}
@@ -1,67 +1,135 @@
 package httpclienttest
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"sync"
+	"testing"
+
+	"github.com/krostar/httpclient"
+)
+
+// DoerStubOrder defines how DoerStub selects the configured call to consume for a request.
+type DoerStubOrder uint8
+
+const (
+	// DoerStubOrderStrict goes through each configured calls in order.
+	// If the first remaining call has a matcher that does not match the request, an error is returned.
+	DoerStubOrderStrict DoerStubOrder = iota
+	// DoerStubOrderFlexible goes through each configured calls in order, and consumes the first one that matches the request.
+	// Calls whose matcher does not match the request are skipped.
+	DoerStubOrderFlexible
+	// DoerStubOrderLIFO goes through each configured calls in reverse order, and consumes the last one that matches the request.
+	// Calls whose matcher does not match the request are skipped.
+	DoerStubOrderLIFO
 )
 
 // NewDoerStub returns a new stubbed Doer.
-// See DoerStubCall for how to configure calls.
+// If strictOrder is true, it is equivalent to NewDoerStubWithOrder(calls, DoerStubOrderStrict),
+// otherwise it is equivalent to NewDoerStubWithOrder(calls, DoerStubOrderFlexible).
 func NewDoerStub(calls []DoerStubCall, strictOrder bool) *DoerStub {
+	if strictOrder {
+		return NewDoerStubWithOrder(calls, DoerStubOrderStrict)
+	}
+	return NewDoerStubWithOrder(calls, DoerStubOrderFlexible)
+}
+
+// NewDoerStubWithOrder returns a new stubbed Doer which selects calls using the provided order.
+// See DoerStubCall for how to configure calls. Calls are cloned, so the same calls can be used by multiple stubs.
+func NewDoerStubWithOrder(calls []DoerStubCall, order DoerStubOrder) *DoerStub {
 	copied := make([]DoerStubCall, len(calls))
-	copy(copied, calls)
+	for i, call := range calls {
+		copied[i] = call.Clone()
+	}
 	return &DoerStub{
-		calls:       copied,
-		strictOrder: strictOrder,
+		calls: copied,
+		order: order,
 	}
 }
 
+// NewDoerStubWithFallback returns a new stubbed Doer which selects calls using the provided order,
+// and passes requests that do not match any call to the provided fallback instead of returning an error.
+// It is useful to stub some requests only, the other ones being handled by a real, or recording, Doer.
+func NewDoerStubWithFallback(calls []DoerStubCall, order DoerStubOrder, fallback httpclient.Doer) *DoerStub {
+	stub := NewDoerStubWithOrder(calls, order)
+	stub.fallback = fallback
+	return stub
+}
+
 // DoerStub implements Doer and returns pre-configured calls.
 // It is safe to call it concurrently.
 type DoerStub struct {
-	m           sync.Mutex
-	strictOrder bool
-	calls       []DoerStubCall
+	// DefaultMatcher, if set, is checked against every request in addition to the matcher of the call.
+	// A request that does not match it fails without consuming any call. It must be set before the stub is used.
+	DefaultMatcher RequestMatcher
+
+	m        sync.Mutex
+	order    DoerStubOrder
+	calls    []DoerStubCall
+	fallback httpclient.Doer
 }
 
 // Do wraps the underlying doer call and returns pre-configured responses.
-// If strictOrder is true, Doer will go through each configured calls in order
-// and if the request matcher is set and don't match the request, an error will be returned.
-// Otherwise, if strictOrder is false, Doer will go through each configured calls in order
-// but if the request matcher is set and don't match the request, the call will be skipped and the next will be tried.
-// If no calls are remaining, or if structOrder is false and no call match, an error will be returned.
+// The call to consume is selected according to the configured order, see DoerStubOrder for details.
+// The request must match the default matcher, if set.
+// If no calls are remaining, or if no call match, the request is passed to the fallback if set, otherwise an error will be returned.
 func (d *DoerStub) Do(req *http.Request) (*http.Response, error) {
+	if d.DefaultMatcher != nil {
+		if err := d.DefaultMatcher.MatchRequest(req); err != nil {
+			return nil, fmt.Errorf("request does not match default matcher: %v", err)
+		}
+	}
+
 	d.m.Lock()
-	defer d.m.Unlock()
 
-	idx := -1
+	idx, err := d.selectCall(req)
+	if idx == -1 {
+		d.m.Unlock()
 
-	for i, call := range d.calls {
-		if call.Matcher == nil {
-			idx = i
-			break
+		switch {
+		case d.fallback != nil:
+			return d.fallback.Do(req)
+		case err != nil:
+			return nil, err
+		default:
+			return nil, errors.New("http doer not configured for this call")
 		}
+	}
 
-		if err := call.Matcher.MatchRequest(req); err == nil {
-			idx = i
-			break
-		} else if d.strictOrder {
-			return nil, fmt.Errorf("request does not match: %v", err)
+	call := d.calls[idx]
+	d.calls = append(d.calls[:idx], d.calls[idx+1:]...)
+	d.m.Unlock()
+
+	return call.Response, call.Error
+}
+
+func (d *DoerStub) selectCall(req *http.Request) (int, error) {
+	indexes := make([]int, len(d.calls))
+	for i := range d.calls {
+		if d.order == DoerStubOrderLIFO {
+			indexes[i] = len(d.calls) - 1 - i
 		} else {
-			continue
+			indexes[i] = i
 		}
 	}
 
-	if idx == -1 {
-		return nil, errors.New("http doer not configured for this call")
-	}
+	for _, i := range indexes {
+		call := d.calls[i]
+		if call.Matcher == nil {
+			return i, nil
+		}
 
-	call := d.calls[idx]
-	d.calls = append(d.calls[:idx], d.calls[idx+1:]...)
+		if err := call.Matcher.MatchRequest(req); err == nil {
+			return i, nil
+		} else if d.order == DoerStubOrderStrict {
+			return -1, fmt.Errorf("request does not match: %v", err)
+		}
+	}
 
-	return call.Response, call.Error
+	return -1, nil
 }
 
 // RemainingCalls returns calls that were not made but configured.
@@ -74,6 +142,20 @@ func (d *DoerStub) RemainingCalls() []DoerStubCall {
 	return calls
 }
 
+// VerifyWith reports, using the provided testing.TB, every configured calls that were not made.
+// It is a convenient replacement of asserting that RemainingCalls is empty.
+func (d *DoerStub) VerifyWith(t testing.TB) {
+	t.Helper()
+
+	for _, call := range d.RemainingCalls() {
+		var status int
+		if call.Response != nil {
+			status = call.Response.StatusCode
+		}
+		t.Errorf("configured call was not made: matcher=%T response status=%d error=%v", call.Matcher, status, call.Error)
+	}
+}
+
 // DoerStubCall define the configuration of a call.
 // If a matcher is not set, the duo 'response,error' will be returned regardless of the request.
 // Otherwise, the request will be checked against matcher and duo 'response,error' will be returned only if the request match.
@@ -83,3 +165,72 @@ type DoerStubCall struct {
 	Response *http.Response
 	Error    error
 }
+
+// Clone returns a copy of the call whose response, if any, is copied as well.
+// The response body is read lazily, on the first read of the body of the call or of one of its copies, and buffered in memory:
+// the call response body is replaced by a body replaying the buffered content, shared by every copy,
+// so each one can be read independently. It is not safe to clone the same call concurrently.
+func (c DoerStubCall) Clone() DoerStubCall {
+	if c.Response == nil {
+		return c
+	}
+
+	resp := *c.Response
+	resp.Header = c.Response.Header.Clone()
+	resp.Trailer = c.Response.Trailer.Clone()
+
+	if c.Response.Body != nil {
+		body, isReplayed := c.Response.Body.(*lazyReplayedBody)
+		if !isReplayed {
+			body = &lazyReplayedBody{source: &bufferedBody{body: c.Response.Body}}
+			c.Response.Body = body
+		}
+		resp.Body = &lazyReplayedBody{source: body.source}
+	}
+
+	c.Response = &resp
+	return c
+}
+
+// bufferedBody reads and closes the underlying body once, on first use.
+type bufferedBody struct {
+	once sync.Once
+	body io.ReadCloser
+	raw  []byte
+	err  error
+}
+
+func (b *bufferedBody) load() ([]byte, error) {
+	b.once.Do(func() {
+		b.raw, b.err = io.ReadAll(b.body)
+		_ = b.body.Close()
+	})
+	return b.raw, b.err
+}
+
+// lazyReplayedBody replays the content of the provided buffered body, which is loaded on first read.
+type lazyReplayedBody struct {
+	source *bufferedBody
+	reader io.Reader
+}
+
+func (b *lazyReplayedBody) Read(p []byte) (int, error) {
+	if b.reader == nil {
+		b.reader = newReplayedReader(b.source.load())
+	}
+	return b.reader.Read(p)
+}
+
+func (*lazyReplayedBody) Close() error { return nil }
+
+// newReplayedReader returns a reader of the provided content, then failing with the provided error, if any.
+func newReplayedReader(raw []byte, err error) io.Reader {
+	if err == nil {
+		return bytes.NewReader(raw)
+	}
+	return io.MultiReader(bytes.NewReader(raw), errReader{err: err})
+}
+
+type errReader struct{ err error }
+
+func (r errReader) Read([]byte) (int, error) { return 0, r.err }
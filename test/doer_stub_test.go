@@ -3,7 +3,10 @@ package httpclienttest
 import (
 	"context"
 	"errors"
+	"fmt"
+	"io"
 	"net/http"
+	"strings"
 	"testing"
 
 	"gotest.tools/v3/assert"
@@ -120,4 +123,204 @@ func Test_DoerStub(t *testing.T) {
 			assert.Check(t, resp == nil)
 		})
 	})
+	t.Run("lifo order", func(t *testing.T) {
+		lifoCalls := []DoerStubCall{
+			{
+				Matcher:  NewRequestMatcherBuilder().Method(http.MethodGet),
+				Response: &http.Response{StatusCode: http.StatusOK},
+			}, {
+				Matcher:  NewRequestMatcherBuilder().Method(http.MethodGet),
+				Response: &http.Response{StatusCode: http.StatusCreated},
+			}, {
+				Matcher:  NewRequestMatcherBuilder().Method(http.MethodPost),
+				Response: &http.Response{StatusCode: http.StatusTeapot},
+			},
+		}
+
+		t.Run("most recent matching call is consumed first", func(t *testing.T) {
+			client := NewDoerStubWithOrder(lifoCalls, DoerStubOrderLIFO)
+
+			resp, err := client.Do(newHTTPRequest(t, http.MethodGet))
+			assert.NilError(t, err)
+			assert.Check(t, resp.StatusCode == http.StatusCreated)
+
+			resp, err = client.Do(newHTTPRequest(t, http.MethodPost))
+			assert.NilError(t, err)
+			assert.Check(t, resp.StatusCode == http.StatusTeapot)
+
+			resp, err = client.Do(newHTTPRequest(t, http.MethodGet))
+			assert.NilError(t, err)
+			assert.Check(t, resp.StatusCode == http.StatusOK)
+
+			assert.Check(t, len(client.RemainingCalls()) == 0)
+		})
+
+		t.Run("no matching calls", func(t *testing.T) {
+			client := NewDoerStubWithOrder(lifoCalls, DoerStubOrderLIFO)
+
+			resp, err := client.Do(newHTTPRequest(t, http.MethodDelete))
+			assert.ErrorContains(t, err, "http doer not configured for this call")
+			assert.Check(t, resp == nil)
+
+			assert.Check(t, len(client.RemainingCalls()) == 3)
+		})
+	})
+}
+
+func Test_DoerStub_fallback(t *testing.T) {
+	newHTTPRequest := func(t *testing.T, method string) *http.Request {
+		req, err := http.NewRequestWithContext(context.Background(), method, "/", nil)
+		assert.NilError(t, err)
+		return req
+	}
+
+	fallback := NewDoerSpy(NewDoerStub([]DoerStubCall{
+		{Response: &http.Response{StatusCode: http.StatusAccepted}},
+		{Response: &http.Response{StatusCode: http.StatusAccepted}},
+	}, false))
+
+	for _, order := range []DoerStubOrder{DoerStubOrderStrict, DoerStubOrderFlexible} {
+		client := NewDoerStubWithFallback([]DoerStubCall{{
+			Matcher:  NewRequestMatcherBuilder().Method(http.MethodGet),
+			Response: &http.Response{StatusCode: http.StatusOK},
+		}}, order, fallback)
+
+		resp, err := client.Do(newHTTPRequest(t, http.MethodPost))
+		assert.NilError(t, err)
+		assert.Check(t, resp.StatusCode == http.StatusAccepted)
+
+		resp, err = client.Do(newHTTPRequest(t, http.MethodGet))
+		assert.NilError(t, err)
+		assert.Check(t, resp.StatusCode == http.StatusOK)
+
+		assert.Check(t, len(client.RemainingCalls()) == 0)
+	}
+
+	calls := fallback.Calls()
+	assert.Assert(t, len(calls) == 2)
+	assert.Check(t, calls[0].InputRequest.Method == http.MethodPost)
+	assert.Check(t, calls[1].InputRequest.Method == http.MethodPost)
+}
+
+func Test_DoerStub_DefaultMatcher(t *testing.T) {
+	client := NewDoerStub([]DoerStubCall{
+		{
+			Matcher:  NewRequestMatcherBuilder().Method(http.MethodGet),
+			Response: &http.Response{StatusCode: http.StatusOK},
+		}, {
+			Response: &http.Response{StatusCode: http.StatusAccepted},
+		},
+	}, true)
+	client.DefaultMatcher = NewRequestMatcherBuilder().HeadersContains(http.Header{"Authorization": {"Bearer foo"}})
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, "/", nil)
+	assert.NilError(t, err)
+
+	resp, err := client.Do(req)
+	assert.ErrorContains(t, err, "request does not match default matcher")
+	assert.Check(t, resp == nil)
+	assert.Check(t, len(client.RemainingCalls()) == 2)
+
+	req.Header.Set("Authorization", "Bearer foo")
+	resp, err = client.Do(req)
+	assert.NilError(t, err)
+	assert.Check(t, resp.StatusCode == http.StatusOK)
+
+	req.Method = http.MethodPost
+	resp, err = client.Do(req)
+	assert.NilError(t, err)
+	assert.Check(t, resp.StatusCode == http.StatusAccepted)
+}
+
+func Test_DoerStubCall_Clone(t *testing.T) {
+	calls := []DoerStubCall{{
+		Response: &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Hello": {"world"}},
+			Body:       io.NopCloser(strings.NewReader("hello world!")),
+		},
+	}}
+
+	for i := 0; i < 2; i++ {
+		req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, "/", nil)
+		assert.NilError(t, err)
+
+		resp, err := NewDoerStub(calls, true).Do(req)
+		assert.NilError(t, err)
+		assert.Check(t, resp != calls[0].Response)
+		assert.Check(t, resp.Header.Get("Hello") == "world")
+
+		body, err := io.ReadAll(resp.Body)
+		assert.NilError(t, err)
+		assert.Check(t, cmp.Equal(string(body), "hello world!"))
+	}
+
+	body, err := io.ReadAll(calls[0].Response.Body)
+	assert.NilError(t, err)
+	assert.Check(t, cmp.Equal(string(body), "hello world!"))
+
+	t.Run("streamed body", func(t *testing.T) {
+		reader, writer := io.Pipe()
+		stub := NewDoerStub([]DoerStubCall{{Response: &http.Response{StatusCode: http.StatusOK, Body: reader}}}, true)
+
+		go func() {
+			_, _ = writer.Write([]byte("streamed"))
+			_ = writer.CloseWithError(errors.New("stream broken"))
+		}()
+
+		req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, "/", nil)
+		assert.NilError(t, err)
+
+		resp, err := stub.Do(req)
+		assert.NilError(t, err)
+
+		body, err := io.ReadAll(resp.Body)
+		assert.Error(t, err, "stream broken")
+		assert.Check(t, cmp.Equal(string(body), "streamed"))
+	})
+
+	clone := DoerStubCall{Error: errors.New("boom")}.Clone()
+	assert.Check(t, clone.Response == nil)
+	assert.Check(t, cmp.ErrorContains(clone.Error, "boom"))
+}
+
+func Test_DoerStub_VerifyWith(t *testing.T) {
+	client := NewDoerStub([]DoerStubCall{
+		{
+			Matcher:  NewRequestMatcherBuilder().Method(http.MethodGet),
+			Response: &http.Response{StatusCode: http.StatusOK},
+		}, {
+			Response: &http.Response{StatusCode: http.StatusTeapot},
+			Error:    errors.New("boom"),
+		},
+	}, false)
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, "/", nil)
+	assert.NilError(t, err)
+	_, err = client.Do(req)
+	assert.NilError(t, err)
+
+	fakeT := &fakeTB{TB: t}
+	client.VerifyWith(fakeT)
+	assert.Check(t, cmp.DeepEqual(fakeT.errors, []string{
+		"configured call was not made: matcher=<nil> response status=418 error=boom",
+	}))
+
+	_, err = client.Do(req)
+	assert.ErrorContains(t, err, "boom")
+
+	fakeT = &fakeTB{TB: t}
+	client.VerifyWith(fakeT)
+	assert.Check(t, len(fakeT.errors) == 0)
+}
+
+type fakeTB struct {
+	testing.TB
+	errors []string
+}
+
+func (*fakeTB) Helper() {}
+
+func (f *fakeTB) Errorf(format string, args ...any) {
+	f.errors = append(f.errors, fmt.Sprintf(format, args...))
 }
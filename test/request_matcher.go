@@ -2,7 +2,9 @@ package httpclienttest
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -20,9 +22,25 @@ type RequestMatcher interface {
 	MatchRequest(req *http.Request) error
 }
 
+// AssertBuilderMatches builds the request using the provided builder, and asserts it against the provided matcher.
+// As the request is built, bodies that can't be replayed are consumed by the assertion.
+func AssertBuilderMatches(ctx context.Context, b *httpclient.RequestBuilder, m RequestMatcher) error {
+	req, err := b.Request(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to build request: %v", err)
+	}
+
+	if err := m.MatchRequest(req); err != nil {
+		return fmt.Errorf("request does not match: %v", err)
+	}
+
+	return nil
+}
+
 // RequestMatcherBuilder stores assertions and implements RequestMatcher.
 type RequestMatcherBuilder struct {
 	assertions []func(*http.Request) error
+	labels     map[int]string
 }
 
 // NewRequestMatcherBuilder creates a new empty RequestMatcherBuilder.
@@ -30,6 +48,68 @@ func NewRequestMatcherBuilder() *RequestMatcherBuilder {
 	return new(RequestMatcherBuilder)
 }
 
+// MatchOption configures the parts of the reference request asserted by the matcher created by NewRequestMatcherFromRequest.
+type MatchOption func(*matchOptions)
+
+type matchOptions struct {
+	ignoreMethod bool
+	ignoreHost   bool
+	ignorePath   bool
+	queryParams  bool
+	headers      []string
+}
+
+// IgnoreMethod does not assert the request method.
+func IgnoreMethod() MatchOption { return func(o *matchOptions) { o.ignoreMethod = true } }
+
+// IgnoreHost does not assert the request url host.
+func IgnoreHost() MatchOption { return func(o *matchOptions) { o.ignoreHost = true } }
+
+// IgnorePath does not assert the request url path.
+func IgnorePath() MatchOption { return func(o *matchOptions) { o.ignorePath = true } }
+
+// MatchQueryParams asserts the request url query params contain the reference request ones.
+func MatchQueryParams() MatchOption { return func(o *matchOptions) { o.queryParams = true } }
+
+// MatchHeaders asserts the provided headers of the request are the reference request ones.
+func MatchHeaders(keys ...string) MatchOption {
+	return func(o *matchOptions) { o.headers = append(o.headers, keys...) }
+}
+
+// NewRequestMatcherFromRequest creates a RequestMatcherBuilder asserting the request matches the provided reference request.
+// By default, method, url host and url path are asserted, which can be changed with the provided options.
+func NewRequestMatcherFromRequest(ref *http.Request, opts ...MatchOption) *RequestMatcherBuilder {
+	var o matchOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	b := NewRequestMatcherBuilder()
+
+	if !o.ignoreMethod {
+		b.Method(ref.Method).Labeled("method")
+	}
+	if !o.ignoreHost {
+		b.URLHost(ref.URL.Host).Labeled("url host")
+	}
+	if !o.ignorePath {
+		b.URLPath(ref.URL.Path).Labeled("url path")
+	}
+	if o.queryParams {
+		b.URLQueryParamsContains(ref.URL.Query()).Labeled("url query params")
+	}
+	if len(o.headers) > 0 {
+		headers := make(http.Header, len(o.headers))
+		for _, key := range o.headers {
+			key = http.CanonicalHeaderKey(key)
+			headers[key] = ref.Header.Values(key)
+		}
+		b.HeadersContains(headers).Labeled("headers")
+	}
+
+	return b
+}
+
 // Method asserts that the provided method matches request.Method.
 func (b *RequestMatcherBuilder) Method(method string) *RequestMatcherBuilder {
 	b.assertions = append(b.assertions, func(req *http.Request) error {
@@ -41,6 +121,17 @@ func (b *RequestMatcherBuilder) Method(method string) *RequestMatcherBuilder {
 	return b
 }
 
+// ProtoAtLeast asserts that the request protocol version is at least major.minor.
+func (b *RequestMatcherBuilder) ProtoAtLeast(major, minor int) *RequestMatcherBuilder {
+	b.assertions = append(b.assertions, func(req *http.Request) error {
+		if !req.ProtoAtLeast(major, minor) {
+			return fmt.Errorf("request protocol %d.%d is below %d.%d", req.ProtoMajor, req.ProtoMinor, major, minor)
+		}
+		return nil
+	})
+	return b
+}
+
 // URLHost asserts that the provided host matches request.URL.Host.
 func (b *RequestMatcherBuilder) URLHost(host string) *RequestMatcherBuilder {
 	b.assertions = append(b.assertions, func(req *http.Request) error {
@@ -63,6 +154,17 @@ func (b *RequestMatcherBuilder) URLPath(path string) *RequestMatcherBuilder {
 	return b
 }
 
+// NoQueryParams asserts that the request url has no query, which catches params set by mistake, for instance by API defaults.
+func (b *RequestMatcherBuilder) NoQueryParams() *RequestMatcherBuilder {
+	b.assertions = append(b.assertions, func(req *http.Request) error {
+		if req.URL.RawQuery != "" {
+			return fmt.Errorf("request url query %q is not empty", req.URL.RawQuery)
+		}
+		return nil
+	})
+	return b
+}
+
 // URLQueryParamsContains asserts that the provided url values are contained in request.URL.Query().
 func (b *RequestMatcherBuilder) URLQueryParamsContains(params url.Values) *RequestMatcherBuilder {
 	b.assertions = append(b.assertions, func(req *http.Request) error {
@@ -181,11 +283,84 @@ func (b *RequestMatcherBuilder) BodyJSON(compareWith any, getDest func() any, st
 	return b
 }
 
+// BodyJSONEqual asserts that request's body is a JSON semantically equal to the provided JSON,
+// regardless of keys order and whitespaces. The request body is restored.
+func (b *RequestMatcherBuilder) BodyJSONEqual(expectedJSON string) *RequestMatcherBuilder {
+	b.assertions = append(b.assertions, func(req *http.Request) error {
+		var expected any
+		if err := json.Unmarshal([]byte(expectedJSON), &expected); err != nil {
+			return fmt.Errorf("unable to parse expected json: %v", err)
+		}
+
+		raw, err := io.ReadAll(req.Body)
+		if err != nil {
+			return fmt.Errorf("unable to read body: %v", err)
+		}
+		req.Body = io.NopCloser(bytes.NewReader(raw))
+
+		var actual any
+		if err := json.Unmarshal(raw, &actual); err != nil {
+			return fmt.Errorf("unable to parse json: %v", err)
+		}
+
+		if diff := gocmp.Diff(actual, expected); diff != "" {
+			return fmt.Errorf("json does not match: %s", diff)
+		}
+
+		return nil
+	})
+	return b
+}
+
+// BodyStreamed asserts whenever the request body is streamed, or buffered in memory.
+// A body is considered buffered if it can be replayed with request.GetBody, like the ones set with SendJSON,
+// and streamed otherwise, like readers set with Send. As server side requests never have GetBody set,
+// this assertion is only relevant for client side requests, like the ones received by DoerStub or AssertBuilderMatches.
+func (b *RequestMatcherBuilder) BodyStreamed(expected bool) *RequestMatcherBuilder {
+	b.assertions = append(b.assertions, func(req *http.Request) error {
+		if req.Body == nil || req.Body == http.NoBody {
+			return errors.New("request has no body")
+		}
+
+		if streamed := req.GetBody == nil; streamed != expected {
+			if streamed {
+				return errors.New("request body is streamed, expected it to be buffered")
+			}
+			return fmt.Errorf("request body is buffered with content length %d, expected it to be streamed", req.ContentLength)
+		}
+
+		return nil
+	})
+	return b
+}
+
+// Labeled sets a label to the last added assertion, used to prefix its error.
+// Example: NewRequestMatcherBuilder().Method(http.MethodGet).Labeled("method check").
+func (b *RequestMatcherBuilder) Labeled(label string) *RequestMatcherBuilder {
+	if len(b.assertions) == 0 {
+		return b
+	}
+
+	if b.labels == nil {
+		b.labels = make(map[int]string)
+	}
+	b.labels[len(b.assertions)-1] = label
+
+	return b
+}
+
 // MatchRequest implements RequestMatcher and asserts all built assertions.
+// Each assertion error is prefixed with the assertion label, or its index if it has no label.
 func (b *RequestMatcherBuilder) MatchRequest(req *http.Request) error {
 	var errs []error
-	for _, assertion := range b.assertions {
-		errs = append(errs, assertion(req))
+	for i, assertion := range b.assertions {
+		if err := assertion(req); err != nil {
+			label, found := b.labels[i]
+			if !found {
+				label = fmt.Sprintf("assertion #%d", i)
+			}
+			errs = append(errs, fmt.Errorf("%s: %w", label, err))
+		}
 	}
 	return multierr.Combine(errs...)
 }
@@ -6,12 +6,16 @@ import (
 	"encoding/json"
 	"io"
 	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"strings"
 	"testing"
 
+	"go.uber.org/multierr"
 	"gotest.tools/v3/assert"
 	"gotest.tools/v3/assert/cmp"
+
+	"github.com/krostar/httpclient"
 )
 
 func Test_RequestMatcherBuilder(t *testing.T) {
@@ -42,6 +46,20 @@ func Test_RequestMatcherBuilder(t *testing.T) {
 			setup:         func(b *RequestMatcherBuilder) { b.Method(http.MethodGet) },
 			errorContains: []string{`request method "POST" != "GET"`},
 		},
+		"ProtoAtLeast ok": {
+			request: func() *http.Request {
+				req := newRequest(http.MethodGet, "/", nil)
+				req.Proto, req.ProtoMajor, req.ProtoMinor = "HTTP/2.0", 2, 0
+				return req
+			},
+			setup:         func(b *RequestMatcherBuilder) { b.ProtoAtLeast(2, 0) },
+			errorContains: nil,
+		},
+		"ProtoAtLeast ko": {
+			request:       func() *http.Request { return newRequest(http.MethodGet, "/", nil) },
+			setup:         func(b *RequestMatcherBuilder) { b.ProtoAtLeast(2, 0) },
+			errorContains: []string{"request protocol 1.1 is below 2.0"},
+		},
 		"URLHost ok": {
 			request:       func() *http.Request { return newRequest(http.MethodGet, "http://example.com/", nil) },
 			setup:         func(b *RequestMatcherBuilder) { b.URLHost("example.com") },
@@ -261,3 +279,212 @@ func Test_RequestMatcherBuilder(t *testing.T) {
 		})
 	}
 }
+
+func Test_AssertBuilderMatches(t *testing.T) {
+	type body struct {
+		Hello string `json:"hello"`
+	}
+
+	newBuilder := func() *httpclient.RequestBuilder {
+		return httpclient.NewRequest(http.MethodPost, "http://localhost/foo").SendJSON(body{Hello: "world"})
+	}
+
+	t.Run("ok", func(t *testing.T) {
+		assert.NilError(t, AssertBuilderMatches(context.Background(), newBuilder(), NewRequestMatcherBuilder().
+			Method(http.MethodPost).
+			BodyJSON(&body{Hello: "world"}, func() any { return new(body) }, true),
+		))
+	})
+
+	t.Run("ko", func(t *testing.T) {
+		t.Run("unable to build request", func(t *testing.T) {
+			assert.ErrorContains(t,
+				AssertBuilderMatches(context.Background(), httpclient.NewRequest(`\`, "http://localhost"), NewRequestMatcherBuilder()),
+				"unable to build request",
+			)
+		})
+
+		t.Run("request does not match", func(t *testing.T) {
+			assert.ErrorContains(t,
+				AssertBuilderMatches(context.Background(), newBuilder(), NewRequestMatcherBuilder().
+					BodyJSON(&body{Hello: "notworld"}, func() any { return new(body) }, true),
+				),
+				"request does not match: assertion #0: json does not match",
+			)
+		})
+	})
+}
+
+func Test_RequestMatcherBuilder_Labeled(t *testing.T) {
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, "/foo", nil)
+	assert.NilError(t, err)
+
+	err = NewRequestMatcherBuilder().
+		Labeled("ignored without assertion").
+		Method(http.MethodGet).Labeled("method check").
+		URLPath("/foo").
+		URLPath("/bar").Labeled("path check").
+		URLHost("example.com").
+		MatchRequest(req)
+
+	assert.Check(t, cmp.ErrorContains(err, `method check: request method "POST" != "GET"`))
+	assert.Check(t, cmp.ErrorContains(err, `path check: request url path "/foo" != "/bar"`))
+	assert.Check(t, cmp.ErrorContains(err, `assertion #3: request url host "" != "example.com"`))
+	assert.Check(t, !strings.Contains(err.Error(), "ignored"))
+	assert.Check(t, cmp.Len(multierr.Errors(err), 3))
+}
+
+func Test_RequestMatcherBuilder_ProtoAtLeast(t *testing.T) {
+	for name, enableHTTP2 := range map[string]bool{"http/1.1": false, "http/2": true} {
+		enableHTTP2 := enableHTTP2
+
+		t.Run(name, func(t *testing.T) {
+			var matchErr error
+
+			httpServer := httptest.NewUnstartedServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+				matchErr = NewRequestMatcherBuilder().ProtoAtLeast(2, 0).MatchRequest(r)
+				rw.WriteHeader(http.StatusOK)
+			}))
+			httpServer.EnableHTTP2 = enableHTTP2
+			httpServer.StartTLS()
+			defer httpServer.Close()
+
+			req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, httpServer.URL, nil)
+			assert.NilError(t, err)
+			resp, err := httpServer.Client().Do(req)
+			assert.NilError(t, err)
+			assert.NilError(t, resp.Body.Close())
+
+			if enableHTTP2 {
+				assert.NilError(t, matchErr)
+			} else {
+				assert.ErrorContains(t, matchErr, "request protocol 1.1 is below 2.0")
+			}
+		})
+	}
+}
+
+func Test_RequestMatcherBuilder_NoQueryParams(t *testing.T) {
+	newRequest := func(target string) *http.Request {
+		req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, target, nil)
+		assert.NilError(t, err)
+		return req
+	}
+
+	assert.NilError(t, NewRequestMatcherBuilder().NoQueryParams().MatchRequest(newRequest("http://localhost/foo")))
+	assert.ErrorContains(t, NewRequestMatcherBuilder().NoQueryParams().MatchRequest(newRequest("http://localhost/foo?bar=baz")),
+		`request url query "bar=baz" is not empty`,
+	)
+}
+
+func Test_RequestMatcherBuilder_BodyStreamed(t *testing.T) {
+	buffered := func() *httpclient.RequestBuilder {
+		return httpclient.NewRequest(http.MethodPost, "http://localhost").SendJSON(map[string]string{"hello": "world"})
+	}
+	streamed := func() *httpclient.RequestBuilder {
+		return httpclient.NewRequest(http.MethodPost, "http://localhost").Send(io.MultiReader(strings.NewReader("hello")))
+	}
+
+	t.Run("ok", func(t *testing.T) {
+		assert.NilError(t, AssertBuilderMatches(context.Background(), buffered(), NewRequestMatcherBuilder().BodyStreamed(false)))
+		assert.NilError(t, AssertBuilderMatches(context.Background(), streamed(), NewRequestMatcherBuilder().BodyStreamed(true)))
+	})
+
+	t.Run("ko", func(t *testing.T) {
+		assert.ErrorContains(t,
+			AssertBuilderMatches(context.Background(), buffered(), NewRequestMatcherBuilder().BodyStreamed(true)),
+			"request body is buffered with content length 17, expected it to be streamed",
+		)
+		assert.ErrorContains(t,
+			AssertBuilderMatches(context.Background(), streamed(), NewRequestMatcherBuilder().BodyStreamed(false)),
+			"request body is streamed, expected it to be buffered",
+		)
+		assert.ErrorContains(t,
+			AssertBuilderMatches(context.Background(), httpclient.NewRequest(http.MethodGet, "http://localhost"), NewRequestMatcherBuilder().BodyStreamed(true)),
+			"request has no body",
+		)
+	})
+}
+
+func Test_RequestMatcherBuilder_BodyJSONEqual(t *testing.T) {
+	newRequest := func(body string) *http.Request {
+		req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, "/", strings.NewReader(body))
+		assert.NilError(t, err)
+		return req
+	}
+
+	t.Run("equal", func(t *testing.T) {
+		req := newRequest(`{"hello":"world","list":[1,2],"nested":{"a":true}}`)
+		assert.NilError(t, NewRequestMatcherBuilder().
+			BodyJSONEqual(`{
+				"nested": {"a": true},
+				"list": [1, 2],
+				"hello": "world"
+			}`).
+			MatchRequest(req),
+		)
+
+		body, err := io.ReadAll(req.Body)
+		assert.NilError(t, err)
+		assert.Equal(t, string(body), `{"hello":"world","list":[1,2],"nested":{"a":true}}`)
+	})
+
+	t.Run("not equal", func(t *testing.T) {
+		assert.ErrorContains(t,
+			NewRequestMatcherBuilder().BodyJSONEqual(`{"hello":"world","list":[2,1]}`).MatchRequest(newRequest(`{"hello":"world","list":[1,2]}`)),
+			"json does not match",
+		)
+		assert.ErrorContains(t,
+			NewRequestMatcherBuilder().BodyJSONEqual(`{"hello":"world"}`).MatchRequest(newRequest(`{"hello":"world","foo":"bar"}`)),
+			"json does not match",
+		)
+	})
+
+	t.Run("invalid json", func(t *testing.T) {
+		assert.ErrorContains(t,
+			NewRequestMatcherBuilder().BodyJSONEqual(`{`).MatchRequest(newRequest(`{}`)),
+			"unable to parse expected json",
+		)
+		assert.ErrorContains(t,
+			NewRequestMatcherBuilder().BodyJSONEqual(`{}`).MatchRequest(newRequest(`{`)),
+			"unable to parse json",
+		)
+	})
+}
+
+func Test_NewRequestMatcherFromRequest(t *testing.T) {
+	newRequest := func(method, target string, header http.Header) *http.Request {
+		req, err := http.NewRequestWithContext(context.Background(), method, target, nil)
+		assert.NilError(t, err)
+		for key, values := range header {
+			req.Header[key] = values
+		}
+		return req
+	}
+
+	ref := newRequest(http.MethodPost, "http://localhost/foo?a=b", http.Header{
+		"Authorization": {"Bearer foo"},
+		"X-Request-Id":  {"1"},
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		req := newRequest(http.MethodPost, "http://localhost/foo?a=b&c=d", http.Header{
+			"Authorization": {"Bearer foo"},
+			"X-Request-Id":  {"2"},
+		})
+
+		assert.NilError(t, NewRequestMatcherFromRequest(ref).MatchRequest(req))
+		assert.NilError(t, NewRequestMatcherFromRequest(ref, MatchQueryParams(), MatchHeaders("authorization")).MatchRequest(req))
+		assert.NilError(t, NewRequestMatcherFromRequest(ref, IgnoreMethod(), IgnoreHost(), IgnorePath()).
+			MatchRequest(newRequest(http.MethodGet, "http://example.com/bar", nil)),
+		)
+	})
+
+	t.Run("ko", func(t *testing.T) {
+		err := NewRequestMatcherFromRequest(ref, MatchQueryParams(), MatchHeaders("X-Request-Id")).
+			MatchRequest(newRequest(http.MethodGet, "http://example.com/bar", http.Header{"X-Request-Id": {"2"}}))
+		for _, label := range []string{"method", "url host", "url path", "url query params", "headers"} {
+			assert.ErrorContains(t, err, label+": ")
+		}
+	})
+}
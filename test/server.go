@@ -5,13 +5,18 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/krostar/httpclient"
 )
 
 // Server runs a server on which one can assert requests.
 type Server struct {
-	do func(url.URL, httpclient.Doer, any) error
+	do        func(url.URL, httpclient.Doer, any) error
+	inProcess bool
 }
 
 // NewServer creates a server.
@@ -20,12 +25,27 @@ func NewServer(do func(serverAddress url.URL, serverDoer httpclient.Doer, checkR
 	return &Server{do: do}
 }
 
+// NewInProcessServer creates a server that does not listen on any port.
+// Requests made with the provided serverDoer are directly handled in memory, which is faster than NewServer.
+// Provided do argument is a fonction that should perform a request.
+func NewInProcessServer(do func(serverAddress url.URL, serverDoer httpclient.Doer, checkResponseFunc any) error) *Server {
+	return &Server{do: do, inProcess: true}
+}
+
 // AssertRequest performs the request and asserts.
+// Exactly one request is expected to be made by the do function, an error is returned otherwise.
 func (srv *Server) AssertRequest(requestExpectations RequestMatcher, writeResponse func(http.ResponseWriter) error, checkResponseFunc any) error {
 	cerr := make(chan error, 1)
 	defer close(cerr)
 
-	httpServer := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+	var requestsCount atomic.Int64
+
+	handler := http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if requestsCount.Add(1) > 1 {
+			rw.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
 		if err := requestExpectations.MatchRequest(r); err != nil {
 			cerr <- fmt.Errorf("request does not match: %v", err)
 			return
@@ -37,17 +57,195 @@ func (srv *Server) AssertRequest(requestExpectations RequestMatcher, writeRespon
 		}
 
 		cerr <- nil
-	}))
-	defer httpServer.Close()
+	})
 
-	httpServerURL, err := url.Parse(httpServer.URL)
+	serverURL, doer, closeServer, err := srv.serve(handler)
 	if err != nil {
-		return fmt.Errorf("unable to parse url %s: %v", httpServer.URL, err)
+		return err
 	}
+	defer closeServer()
 
-	if err := srv.do(*httpServerURL, httpServer.Client(), checkResponseFunc); err != nil {
+	if err := srv.do(serverURL, doer, checkResponseFunc); err != nil {
 		return fmt.Errorf("doer execution failed: %v", err)
 	}
 
+	if count := requestsCount.Load(); count != 1 {
+		return fmt.Errorf("expected 1 request, got %d", count)
+	}
+
 	return <-cerr
 }
+
+// ServerStep defines the expectations and the response of one request asserted by Server.AssertRequests.
+type ServerStep struct {
+	// RequestMatcher asserts the request of the step, if set.
+	RequestMatcher RequestMatcher
+	// WriteResponse writes the response of the step, if set.
+	WriteResponse func(http.ResponseWriter) error
+	// Timeout is the maximum duration to wait for the request of the step, starting once the previous step is completed.
+	// Zero means no timeout.
+	Timeout time.Duration
+}
+
+// NewPreflightServerStep creates a step asserting a CORS preflight request, to be used with Server.AssertRequests.
+// The request is expected to be an OPTIONS request, with Access-Control-Request-Method set to the provided method and,
+// if some are provided, Access-Control-Request-Headers set to the comma separated list of provided headers.
+// The response is written with the provided CORS headers and a 204 No Content status.
+func NewPreflightServerStep(requestMethod string, requestHeaders []string, corsHeaders http.Header) ServerStep {
+	expectedHeaders := http.Header{"Access-Control-Request-Method": {requestMethod}}
+	if len(requestHeaders) > 0 {
+		expectedHeaders["Access-Control-Request-Headers"] = []string{strings.Join(requestHeaders, ",")}
+	}
+
+	return ServerStep{
+		RequestMatcher: NewRequestMatcherBuilder().
+			Method(http.MethodOptions).Labeled("preflight method").
+			HeadersContains(expectedHeaders).Labeled("preflight headers"),
+		WriteResponse: func(rw http.ResponseWriter) error {
+			for key, values := range corsHeaders {
+				rw.Header()[key] = values
+			}
+			rw.WriteHeader(http.StatusNoContent)
+			return nil
+		},
+	}
+}
+
+// AssertRequests performs the requests and asserts each of them against the provided steps, in order.
+// Requests are expected to be sequential: a request received while the previous one is still being handled is an error.
+// Exactly len(steps) requests are expected to be made by the do function, an error is returned otherwise.
+// If a step times out, the error is returned without waiting for the do function to return.
+func (srv *Server) AssertRequests(steps []ServerStep, checkResponseFunc any) error {
+	type stepResult struct {
+		idx int
+		err error
+	}
+
+	var (
+		requestsCount atomic.Int64
+		handling      sync.Mutex
+		cstep         = make(chan stepResult, len(steps))
+	)
+
+	handler := http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		idx := int(requestsCount.Add(1)) - 1
+		if idx >= len(steps) {
+			rw.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		if !handling.TryLock() {
+			rw.WriteHeader(http.StatusInternalServerError)
+			cstep <- stepResult{idx: idx, err: fmt.Errorf("step %d: request received before previous step completed", idx)}
+			return
+		}
+		defer handling.Unlock()
+
+		step := steps[idx]
+
+		if step.RequestMatcher != nil {
+			if err := step.RequestMatcher.MatchRequest(r); err != nil {
+				cstep <- stepResult{idx: idx, err: fmt.Errorf("step %d: request does not match: %v", idx, err)}
+				return
+			}
+		}
+
+		if step.WriteResponse != nil {
+			if err := step.WriteResponse(rw); err != nil {
+				cstep <- stepResult{idx: idx, err: fmt.Errorf("step %d: unable to write response: %v", idx, err)}
+				return
+			}
+		}
+
+		cstep <- stepResult{idx: idx}
+	})
+
+	serverURL, doer, closeServer, err := srv.serve(handler)
+	if err != nil {
+		return err
+	}
+	defer closeServer()
+
+	cdo := make(chan error, 1)
+	go func() { cdo <- srv.do(serverURL, doer, checkResponseFunc) }()
+
+	var doReturned bool
+
+	for i, step := range steps {
+		if doReturned {
+			// all requests are handled before the do function returns, so their results are already buffered
+			select {
+			case result := <-cstep:
+				if result.err != nil {
+					return result.err
+				}
+				continue
+			default:
+				return fmt.Errorf("expected %d requests, got %d", len(steps), requestsCount.Load())
+			}
+		}
+
+		var timeout <-chan time.Time
+		if step.Timeout > 0 {
+			timer := time.NewTimer(step.Timeout)
+			defer timer.Stop()
+			timeout = timer.C
+		}
+
+		select {
+		case result := <-cstep:
+			if result.err != nil {
+				return result.err
+			}
+		case err := <-cdo:
+			doReturned = true
+
+			// the step result, if any, is more relevant than the do error as it is likely the cause of it
+			select {
+			case result := <-cstep:
+				if result.err != nil {
+					return result.err
+				}
+			default:
+				if err == nil {
+					return fmt.Errorf("expected %d requests, got %d", len(steps), requestsCount.Load())
+				}
+			}
+
+			if err != nil {
+				return fmt.Errorf("doer execution failed: %v", err)
+			}
+		case <-timeout:
+			return fmt.Errorf("step %d: request not received within %s", i, step.Timeout)
+		}
+	}
+
+	if !doReturned {
+		if err := <-cdo; err != nil {
+			return fmt.Errorf("doer execution failed: %v", err)
+		}
+	}
+
+	if count := requestsCount.Load(); count != int64(len(steps)) {
+		return fmt.Errorf("expected %d requests, got %d", len(steps), count)
+	}
+
+	return nil
+}
+
+// serve starts a server, or an in-process server, that uses the provided handler.
+func (srv *Server) serve(handler http.Handler) (url.URL, httpclient.Doer, func(), error) {
+	if srv.inProcess {
+		return url.URL{Scheme: "http", Host: "in-process.local"}, DoerFromHandler(handler), func() {}, nil
+	}
+
+	httpServer := httptest.NewServer(handler)
+
+	httpServerURL, err := url.Parse(httpServer.URL)
+	if err != nil {
+		httpServer.Close()
+		return url.URL{}, nil, nil, fmt.Errorf("unable to parse url %s: %v", httpServer.URL, err)
+	}
+
+	return *httpServerURL, httpServer.Client(), httpServer.Close, nil
+}
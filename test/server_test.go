@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"net/url"
 	"testing"
+	"time"
 
 	"gotest.tools/v3/assert"
 
@@ -90,5 +91,253 @@ func Test_TestingServer(t *testing.T) {
 				"unable to write response: boom",
 			)
 		})
+
+		t.Run("more than one request", func(t *testing.T) {
+			srv := NewServer(func(u url.URL, doer httpclient.Doer, checkResponse any) error {
+				for i := 0; i < 2; i++ {
+					_, err := createSomething(doer, u)
+					checkResponse.(func(uint, error))(0, err)
+				}
+				return nil
+			})
+
+			assert.ErrorContains(t, srv.AssertRequest(
+				NewRequestMatcherBuilder().URLPath("/foo"),
+				func(rw http.ResponseWriter) error {
+					rw.WriteHeader(http.StatusTeapot)
+					return nil
+				},
+				func(uint, error) {}),
+				"expected 1 request, got 2",
+			)
+		})
+
+		t.Run("no request", func(t *testing.T) {
+			srv := NewServer(func(url.URL, httpclient.Doer, any) error { return nil })
+
+			assert.ErrorContains(t,
+				srv.AssertRequest(NewRequestMatcherBuilder(), nil, nil),
+				"expected 1 request, got 0",
+			)
+		})
+	})
+}
+
+func Test_TestingInProcessServer(t *testing.T) {
+	srv := NewInProcessServer(func(u url.URL, doer httpclient.Doer, checkResponse any) error {
+		if u.Host != "in-process.local" {
+			return fmt.Errorf("unexpected host %s", u.Host)
+		}
+
+		var header http.Header
+		err := httpclient.NewRequest(http.MethodPost, u.String()+"/foo").
+			Client(doer).
+			SendJSON(map[string]string{"hello": "world"}).
+			Do(context.Background()).
+			OnStatus(http.StatusTeapot, func(resp *http.Response) error {
+				header = resp.Header
+				return nil
+			}).
+			Error()
+		checkResponse.(func(http.Header, error))(header, err)
+		return nil
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		var calledCheckResponse uint
+
+		assert.NilError(t, srv.AssertRequest(
+			NewRequestMatcherBuilder().
+				Method(http.MethodPost).
+				URLPath("/foo").
+				BodyJSON(&map[string]string{"hello": "world"}, func() any { return new(map[string]string) }, true),
+			func(rw http.ResponseWriter) error {
+				rw.Header().Set("Hello", "world")
+				rw.WriteHeader(http.StatusTeapot)
+				return nil
+			},
+			func(header http.Header, err error) {
+				calledCheckResponse++
+				assert.Check(t, header.Get("Hello") == "world")
+				assert.Check(t, err == nil)
+			}),
+		)
+		assert.Check(t, calledCheckResponse == 1)
+	})
+
+	t.Run("ko", func(t *testing.T) {
+		t.Run("request does not match", func(t *testing.T) {
+			assert.ErrorContains(t,
+				srv.AssertRequest(NewRequestMatcherBuilder().URLPath("/notfoo"), nil, func(http.Header, error) {}),
+				"request does not match",
+			)
+		})
+
+		t.Run("unable to write response", func(t *testing.T) {
+			assert.ErrorContains(t, srv.AssertRequest(
+				NewRequestMatcherBuilder().URLPath("/foo"),
+				func(http.ResponseWriter) error { return errors.New("boom") },
+				func(http.Header, error) {}),
+				"unable to write response: boom",
+			)
+		})
+	})
+}
+
+func Test_TestingServer_AssertRequests(t *testing.T) {
+	doRequest := func(doer httpclient.Doer, u url.URL, path string) error {
+		return httpclient.NewRequest(http.MethodGet, u.String()+path).
+			Client(doer).
+			Do(context.Background()).
+			SuccessOnStatus(http.StatusOK).
+			Error()
+	}
+
+	writeOK := func(rw http.ResponseWriter) error {
+		rw.WriteHeader(http.StatusOK)
+		return nil
+	}
+
+	t.Run("ok", func(t *testing.T) {
+		srv := NewInProcessServer(func(u url.URL, doer httpclient.Doer, _ any) error {
+			if err := doRequest(doer, u, "/first"); err != nil {
+				return err
+			}
+			return doRequest(doer, u, "/second")
+		})
+
+		assert.NilError(t, srv.AssertRequests([]ServerStep{
+			{RequestMatcher: NewRequestMatcherBuilder().URLPath("/first"), WriteResponse: writeOK},
+			{RequestMatcher: NewRequestMatcherBuilder().URLPath("/second"), WriteResponse: writeOK, Timeout: time.Second},
+		}, nil))
+	})
+
+	t.Run("ko", func(t *testing.T) {
+		t.Run("step request does not match", func(t *testing.T) {
+			srv := NewServer(func(u url.URL, doer httpclient.Doer, _ any) error {
+				_ = doRequest(doer, u, "/first")
+				_ = doRequest(doer, u, "/first")
+				return nil
+			})
+
+			assert.ErrorContains(t, srv.AssertRequests([]ServerStep{
+				{RequestMatcher: NewRequestMatcherBuilder().URLPath("/first"), WriteResponse: writeOK},
+				{RequestMatcher: NewRequestMatcherBuilder().URLPath("/second"), WriteResponse: writeOK},
+			}, nil), "step 1: request does not match")
+		})
+
+		t.Run("step request never received", func(t *testing.T) {
+			blocked := make(chan struct{})
+			defer close(blocked)
+
+			srv := NewServer(func(u url.URL, doer httpclient.Doer, _ any) error {
+				if err := doRequest(doer, u, "/first"); err != nil {
+					return err
+				}
+				<-blocked // simulate a client stuck after the first response
+				return nil
+			})
+
+			assert.ErrorContains(t, srv.AssertRequests([]ServerStep{
+				{RequestMatcher: NewRequestMatcherBuilder().URLPath("/first"), WriteResponse: writeOK},
+				{RequestMatcher: NewRequestMatcherBuilder().URLPath("/second"), WriteResponse: writeOK, Timeout: 50 * time.Millisecond},
+			}, nil), "step 1: request not received within 50ms")
+		})
+
+		t.Run("not enough requests", func(t *testing.T) {
+			srv := NewInProcessServer(func(u url.URL, doer httpclient.Doer, _ any) error {
+				return doRequest(doer, u, "/first")
+			})
+
+			assert.ErrorContains(t, srv.AssertRequests([]ServerStep{
+				{WriteResponse: writeOK},
+				{WriteResponse: writeOK},
+			}, nil), "expected 2 requests, got 1")
+		})
+
+		t.Run("too many requests", func(t *testing.T) {
+			srv := NewInProcessServer(func(u url.URL, doer httpclient.Doer, _ any) error {
+				_ = doRequest(doer, u, "/first")
+				_ = doRequest(doer, u, "/first")
+				return nil
+			})
+
+			assert.ErrorContains(t, srv.AssertRequests([]ServerStep{{WriteResponse: writeOK}}, nil), "expected 1 requests, got 2")
+		})
+
+		t.Run("concurrent requests", func(t *testing.T) {
+			var (
+				handlingFirst = make(chan struct{})
+				release       = make(chan struct{})
+			)
+
+			srv := NewInProcessServer(func(u url.URL, doer httpclient.Doer, _ any) error {
+				go func() { _ = doRequest(doer, u, "/first") }()
+				<-handlingFirst
+				err := doRequest(doer, u, "/second")
+				close(release)
+				return err
+			})
+
+			assert.ErrorContains(t, srv.AssertRequests([]ServerStep{
+				{WriteResponse: func(rw http.ResponseWriter) error {
+					close(handlingFirst)
+					<-release
+					return writeOK(rw)
+				}},
+				{WriteResponse: writeOK},
+			}, nil), "request received before previous step completed")
+		})
+	})
+}
+
+func Test_NewPreflightServerStep(t *testing.T) {
+	srv := NewInProcessServer(func(u url.URL, doer httpclient.Doer, checkResponse any) error {
+		var allowedOrigin string
+
+		if err := httpclient.NewRequest(http.MethodOptions, u.String()+"/foo").
+			Client(doer).
+			SetHeader("Origin", "https://example.com").
+			SetHeader("Access-Control-Request-Method", http.MethodGet).
+			SetHeader("Access-Control-Request-Headers", "authorization,x-foo").
+			Do(context.Background()).
+			BindHeader(http.StatusNoContent, "Access-Control-Allow-Origin", &allowedOrigin).
+			Error(); err != nil {
+			return err
+		}
+
+		checkResponse.(func(string))(allowedOrigin)
+
+		return httpclient.NewRequest(http.MethodGet, u.String()+"/foo").
+			Client(doer).
+			Do(context.Background()).
+			SuccessOnStatus(http.StatusOK).
+			Error()
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		var allowedOrigin string
+
+		assert.NilError(t, srv.AssertRequests([]ServerStep{
+			NewPreflightServerStep(http.MethodGet, []string{"authorization", "x-foo"}, http.Header{
+				"Access-Control-Allow-Origin":  {"https://example.com"},
+				"Access-Control-Allow-Methods": {"GET"},
+			}),
+			{
+				RequestMatcher: NewRequestMatcherBuilder().Method(http.MethodGet).URLPath("/foo"),
+				WriteResponse: func(rw http.ResponseWriter) error {
+					rw.WriteHeader(http.StatusOK)
+					return nil
+				},
+			},
+		}, func(origin string) { allowedOrigin = origin }))
+		assert.Check(t, allowedOrigin == "https://example.com")
+	})
+
+	t.Run("ko", func(t *testing.T) {
+		assert.ErrorContains(t, srv.AssertRequests([]ServerStep{
+			NewPreflightServerStep(http.MethodPost, nil, nil),
+			{},
+		}, func(string) {}), "step 0: request does not match: preflight headers")
 	})
 }